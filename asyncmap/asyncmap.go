@@ -1,42 +1,308 @@
 package asyncmap
 
 import (
-	"log"
+	"bytes"
+	"cmp"
+	"encoding/binary"
+	"maps"
+	"math/rand"
+	"reflect"
+	"slices"
 	"sync"
+	"sync/atomic"
+	"time"
+	"unsafe"
 )
 
+// now returns the current time. It is a variable so time-based behavior can be controlled.
+var now = time.Now
+
 // SyncMap is a Type Safe, Thread Safe, nil Safe, and reference Safe
 // generic wrapper around Go's sync.Map.
 type SyncMap[K comparable, V any] struct {
-	syncMap   *sync.Map
-	localLock *sync.Mutex
+	// core holds the map's shared state, so that copies of a SyncMap see the same map.
+	core *mapCore[K, V]
+	// parent is set on maps created by Fork; reads fall through to it.
+	parent *SyncMap[K, V]
+}
+
+// mapCore is the state shared by every copy of a SyncMap, allocated once by lazyInit.
+type mapCore[K comparable, V any] struct {
+	// contents points to the table holding the map's entries.
+	contents  atomic.Pointer[table]
+	localLock sync.Mutex
+	// extras holds the state of rarely used features; it is nil until one of them is used.
+	extras atomic.Pointer[extras[K, V]]
 }
 
 // globalLock is used to safely initialize a zero-value SyncMap instance.
 // It is a coarse-grained lock only used once per uninitialized map.
 var globalLock sync.Mutex
 
-// lazyInit ensures the underlying syncMap and localLock are initialized.
+// lazyInit ensures the underlying core is initialized.
 // It uses a double-checked locking pattern with the package-level globalLock.
 func (m *SyncMap[K, V]) lazyInit() {
-	if m.syncMap == nil {
+	if m.core == nil {
 		globalLock.Lock()
 		defer globalLock.Unlock()
-		if m.syncMap == nil {
-			m.syncMap = &sync.Map{}
-			m.localLock = &sync.Mutex{}
+		if m.core == nil {
+			core := &mapCore[K, V]{}
+			core.contents.Store(newTable())
+			m.core = core
+		}
+	}
+}
+
+// SwapContents exchanges the contents of a and b: afterwards a holds what b held and vice versa.
+// Both local locks are taken, in address order so that concurrent swaps cannot deadlock, and each
+// map's contents are replaced in a single atomic step, so readers of either map see the full old
+// or the full new contents, never a mix. This allows building a new map off to the side and then
+// making it live. Per-map settings such as Freeze, Tee replicas and size thresholds stay with
+// their map. Swapping a map with itself, or with a copy of itself, is a no-op.
+// A forked map's contents only make sense over its parent, so SwapContents panics if
+// either map is a fork.
+func SwapContents[K comparable, V any](a, b *SyncMap[K, V]) {
+	a.lazyInit()
+	b.lazyInit()
+	if a.core == b.core {
+		return
+	}
+	if a.parent != nil || b.parent != nil {
+		panic("asyncmap: SwapContents on a forked SyncMap")
+	}
+	a.mustBeWritable()
+	b.mustBeWritable()
+	defer lockPair(a, b)()
+	contentsA, contentsB := a.core.contents.Load(), b.core.contents.Load()
+	a.core.contents.Store(contentsB)
+	b.core.contents.Store(contentsA)
+	a.newVersionGeneration()
+	b.newVersionGeneration()
+	a.notifyIfEmpty()
+	b.notifyIfEmpty()
+}
+
+// lockPair takes the local locks of a and b in address order, so that two goroutines locking
+// the same pair in opposite argument order cannot deadlock, and returns a function that
+// releases them. Maps sharing a lock (copies of one another) are locked once.
+func lockPair[K comparable, V any](a, b *SyncMap[K, V]) (unlock func()) {
+	if a.core == b.core {
+		a.core.localLock.Lock()
+		return a.core.localLock.Unlock
+	}
+	first, second := a, b
+	if uintptr(unsafe.Pointer(first.core)) > uintptr(unsafe.Pointer(second.core)) {
+		first, second = second, first
+	}
+	first.core.localLock.Lock()
+	second.core.localLock.Lock()
+	return func() {
+		second.core.localLock.Unlock()
+		first.core.localLock.Unlock()
+	}
+}
+
+// EqualConsistent reports whether a and b hold exactly the same entries. It takes both local
+// locks (in address order, so it cannot deadlock) and compares snapshots taken while holding
+// them, so composite operations on either map cannot make it see them mid-update.
+// Plain writes such as Store don't take the lock and may still race with the comparison.
+func EqualConsistent[K comparable, V comparable](a, b *SyncMap[K, V]) bool {
+	a.lazyInit()
+	b.lazyInit()
+	defer lockPair(a, b)()
+	return maps.Equal(a.snapshot(), b.snapshot())
+}
+
+// AbsorbConsistent stores every entry of other into m, overwriting matching keys, and if
+// clearOther is true removes them from other, for consolidating shards. Both local locks are
+// taken in address order, as in SwapContents, so the move is atomic with respect to composite
+// operations on either map and concurrent absorbs cannot deadlock. When clearing, each entry is
+// deleted from other before its value is stored into m, so a plain Store racing on other is
+// either moved or left in other, never lost. Absorbing a map into itself, or into a copy of
+// itself, is a no-op.
+func (m *SyncMap[K, V]) AbsorbConsistent(other *SyncMap[K, V], clearOther bool) {
+	m.lazyInit()
+	other.lazyInit()
+	if m.core == other.core {
+		return
+	}
+	m.mustBeWritable()
+	if clearOther {
+		other.mustBeWritable()
+	}
+	defer lockPair(m, other)()
+	if !clearOther {
+		m.storeBatch(other.snapshot())
+		return
+	}
+	other.rangeRaw(func(key, _ any) bool {
+		typedKey, ok := other.typedKey(key)
+		if !ok {
+			return true
+		}
+		value, loaded := other.loadAndDelete(typedKey)
+		if typedValue, ok := other.typedValue(value); loaded && ok {
+			m.store(typedKey, typedValue)
+		}
+		return true
+	})
+}
+
+// Freeze makes the map read-only. After Freeze, every method that writes to the map
+// panics, while reads keep working. Freezing cannot be undone.
+// Freeze is a safety guard against accidental mutation of data meant to be immutable,
+// not a security boundary: values of reference types can still be mutated in place.
+func (m *SyncMap[K, V]) Freeze() {
+	m.lazyInit()
+	m.initExtras().frozen.Store(true)
+}
+
+// IsFrozen reports whether Freeze has been called on the map.
+func (m *SyncMap[K, V]) IsFrozen() bool {
+	m.lazyInit()
+	e := m.loadExtras()
+	return e != nil && e.frozen.Load()
+}
+
+// mustBeWritable panics if the map has been frozen.
+// Every method that writes to the map calls it after lazyInit.
+func (m *SyncMap[K, V]) mustBeWritable() {
+	if m.IsFrozen() {
+		panic("asyncmap: write to frozen SyncMap")
+	}
+}
+
+// Tee registers replica so that every subsequent write to the map (Store, Delete and
+// everything built on them) is mirrored into it. Several replicas may be registered.
+// Existing entries are not copied; combine with Copy or Merge to seed a replica.
+// Mirroring is best-effort and not transactional: each write lands in the map first and
+// then in each replica, and concurrent readers may observe them out of step.
+// Replicas must not tee back into the map, directly or indirectly.
+func (m *SyncMap[K, V]) Tee(replica *SyncMap[K, V]) {
+	m.lazyInit()
+	replica.lazyInit()
+	e := m.initExtras()
+	for {
+		current := e.tees.Load()
+		var tees []*SyncMap[K, V]
+		if current != nil {
+			tees = append(tees, *current...)
+		}
+		tees = append(tees, replica)
+		if e.tees.CompareAndSwap(current, &tees) {
+			return
 		}
 	}
 }
 
+// TypeMismatchCount returns how many times a read found a stored key or value that was not
+// of the map's type and had to skip it. Typed methods can never store such entries, so a
+// nonzero count means the underlying sync.Map is being written through some other path.
+// Stored nils are not counted.
+func (m *SyncMap[K, V]) TypeMismatchCount() uint64 {
+	m.lazyInit()
+	if e := m.loadExtras(); e != nil {
+		return e.mismatches.Load()
+	}
+	return 0
+}
+
+// typedKey asserts a raw key to K, counting the failure as a type mismatch.
+func (m *SyncMap[K, V]) typedKey(key any) (K, bool) {
+	typedKey, ok := key.(K)
+	if !ok && key != nil {
+		m.initExtras().mismatches.Add(1)
+	}
+	return typedKey, ok
+}
+
+// typedValue asserts a raw value to V, counting the failure as a type mismatch
+// unless the value is a stored nil. A stored nil is a valid entry when V is an interface
+// type, whose zero value is nil, so iteration and Len agree on it.
+func (m *SyncMap[K, V]) typedValue(value any) (V, bool) {
+	typedValue, ok := value.(V)
+	if !ok && value == nil {
+		return typedValue, any(typedValue) == nil
+	}
+	if !ok {
+		m.initExtras().mismatches.Add(1)
+	}
+	return typedValue, ok
+}
+
 // Clear removes all entries from the map.
 // It acquires the local lock to ensure atomicity against other composite operations like Range.
 func (m *SyncMap[K, V]) Clear() {
 	m.lazyInit()
-	m.localLock.Lock()
-	defer m.localLock.Unlock()
-	m.syncMap.Range(func(key, _ any) bool {
-		m.syncMap.Delete(key)
+	m.mustBeWritable()
+	m.core.localLock.Lock()
+	defer m.core.localLock.Unlock()
+	m.rangeRaw(func(key, _ any) bool {
+		if typedKey, ok := m.typedKey(key); ok {
+			m.loadAndDelete(typedKey)
+		} else {
+			m.deleteForeign(key)
+		}
+		return true
+	})
+}
+
+// Reset empties the map. sync.Map has no capacity to keep, so instead of deleting entries one
+// by one like Clear, Reset swaps in a fresh, empty table in a single step, which is much cheaper
+// for maps that are repeatedly filled and emptied. Unlike Clear, it does not mirror deletes to
+// Tee replicas, and a Store racing with Reset may land in the discarded table. A forked map is
+// emptied with Clear instead, since its parent's entries must still be hidden.
+func (m *SyncMap[K, V]) Reset() {
+	m.lazyInit()
+	m.mustBeWritable()
+	if m.parent != nil {
+		m.Clear()
+		return
+	}
+	m.core.localLock.Lock()
+	defer m.core.localLock.Unlock()
+	m.core.contents.Store(newTable())
+	m.rearmThreshold()
+	m.newVersionGeneration()
+	m.notifyIfEmpty()
+}
+
+// Rotate empties the map and returns a new SyncMap holding its previous entries, so a finished
+// aggregation window can be processed while new writes accumulate. A write holding the local
+// lock, such as FetchAdd, lands in exactly one of the two maps. A racing plain Store may still
+// land in the returned map after Rotate returns, so it can be missed by a caller that has already
+// read that map; SwapAndRange's guarantee is scoped the same way.
+func (m *SyncMap[K, V]) Rotate() *SyncMap[K, V] {
+	m.lazyInit()
+	m.mustBeWritable()
+	m.core.localLock.Lock()
+	defer m.core.localLock.Unlock()
+	old := NewSyncMap[K, V]()
+	if m.parent != nil {
+		old.BatchStore(m.snapshot())
+		m.rangeRaw(func(key, _ any) bool {
+			if typedKey, ok := m.typedKey(key); ok {
+				m.loadAndDelete(typedKey)
+			}
+			return true
+		})
+		return &old
+	}
+	old.core.contents.Store(m.core.contents.Swap(newTable()))
+	m.rearmThreshold()
+	m.newVersionGeneration()
+	m.notifyIfEmpty()
+	return &old
+}
+
+// SwapAndRange empties the map via Rotate and then calls fn for each entry it held, for
+// periodic metric flushing: fn sees the previous interval while new counts accumulate from zero.
+// The no-loss guarantee covers only writes that hold the local lock, such as FetchAdd and
+// IncrCapped: each is reported by exactly one flush. A plain Store racing with the swap may
+// land in the old table after fn has visited it and go unreported. fn is called as by Range.
+func (m *SyncMap[K, V]) SwapAndRange(fn func(key K, value V)) {
+	m.Rotate().Range(func(key K, value V) bool {
+		fn(key, value)
 		return true
 	})
 }
@@ -45,8 +311,7 @@ func (m *SyncMap[K, V]) Clear() {
 // with values from the provided maps.
 func NewSyncMap[K comparable, V any](maps ...map[K]V) SyncMap[K, V] {
 	var sMap SyncMap[K, V]
-	sMap.syncMap = &sync.Map{}
-	sMap.localLock = &sync.Mutex{}
+	sMap.lazyInit()
 	for _, m := range maps {
 		for key, value := range m {
 			sMap.Store(key, value)
@@ -56,11 +321,18 @@ func NewSyncMap[K comparable, V any](maps ...map[K]V) SyncMap[K, V] {
 }
 
 // Load returns the value stored in the map for a key, or nil/false if no value is present.
-// It enforces type safety and treats stored nil values as "not found".
+// It enforces type safety and treats stored nil values as "not found"; use LoadPresent to
+// tell a stored nil apart from an absent key.
 func (m *SyncMap[K, V]) Load(key K) (V, bool) {
 	m.lazyInit()
-	value, ok := m.syncMap.Load(key)
-	typedValue, typedOk := value.(V)
+	m.recordAccess(key)
+	return m.lookup(key)
+}
+
+// lookup is Load without access counting, for lookups other methods make on their own behalf.
+func (m *SyncMap[K, V]) lookup(key K) (V, bool) {
+	value, ok := m.load(key)
+	typedValue, typedOk := m.typedValue(value)
 	// Key must be found (ok), assertion must succeed (typedOk), and value must not be nil
 	// (nil check handles stored nil pointers/interfaces).
 	return typedValue, (typedOk && ok && value != nil)
@@ -70,7 +342,8 @@ func (m *SyncMap[K, V]) Load(key K) (V, bool) {
 // or the stored value is nil/of the wrong type.
 func (m *SyncMap[K, V]) Get(key K) V {
 	m.lazyInit()
-	value, ok := m.syncMap.Load(key)
+	m.recordAccess(key)
+	value, ok := m.load(key)
 
 	var zero V
 	if !ok {
@@ -81,7 +354,7 @@ func (m *SyncMap[K, V]) Get(key K) V {
 		return zero
 	}
 
-	typedValue, typedOk := value.(V)
+	typedValue, typedOk := m.typedValue(value)
 	if !typedOk {
 		return zero
 	}
@@ -94,11 +367,12 @@ func (m *SyncMap[K, V]) Get(key K) V {
 // If no defaultValue is provided, the zero value of V is used as the default.
 func (m *SyncMap[K, V]) GetOrDefault(key K, defaultValue ...V) V {
 	m.lazyInit()
+	m.recordAccess(key)
 	var df V
 	if len(defaultValue) > 0 {
 		df = defaultValue[0]
 	}
-	value, ok := m.syncMap.Load(key)
+	value, ok := m.load(key)
 
 	// Key not found
 	if !ok {
@@ -111,7 +385,7 @@ func (m *SyncMap[K, V]) GetOrDefault(key K, defaultValue ...V) V {
 	}
 
 	// Type assertion
-	typedValue, typedOk := value.(V)
+	typedValue, typedOk := m.typedValue(value)
 	if !typedOk {
 		return df
 	}
@@ -119,77 +393,516 @@ func (m *SyncMap[K, V]) GetOrDefault(key K, defaultValue ...V) V {
 	return typedValue
 }
 
+// GetOrErr returns the value stored for key, or a *KeyNotFoundError holding key if it is
+// missing under the same rules as Load. The error matches ErrKeyNotFound under errors.Is.
+func (m *SyncMap[K, V]) GetOrErr(key K) (V, error) {
+	value, ok := m.Load(key)
+	if !ok {
+		return value, &KeyNotFoundError{Key: key}
+	}
+	return value, nil
+}
+
+// LoadPresent returns the value stored for key and whether an entry for key exists.
+// Unlike Load, a stored nil is reported as present, so callers can tell an explicit nil
+// apart from an absent key. If the stored value is nil or not a V, the zero value of V is returned.
+func (m *SyncMap[K, V]) LoadPresent(key K) (V, bool) {
+	m.lazyInit()
+	value, ok := m.load(key)
+	typedValue, _ := m.typedValue(value)
+	return typedValue, ok
+}
+
+// Has reports whether the map contains an entry for key.
+// Like LoadPresent, it returns true for a key holding a stored nil.
+func (m *SyncMap[K, V]) Has(key K) bool {
+	_, ok := m.LoadPresent(key)
+	return ok
+}
+
+// GetAll looks up every key and returns the entries that were found along with
+// the keys that were not. found is never nil, and missing preserves the order of keys.
+// A key counts as missing under the same rules as Load.
+func (m *SyncMap[K, V]) GetAll(keys ...K) (found map[K]V, missing []K) {
+	found = make(map[K]V, len(keys))
+	for _, key := range keys {
+		if value, ok := m.Load(key); ok {
+			found[key] = value
+		} else {
+			missing = append(missing, key)
+		}
+	}
+	return found, missing
+}
+
+// GetMulti returns the values for keys in the same order, with the zero value of V for keys
+// that are missing under the same rules as Load. Unlike GetAll, the result lines up with keys
+// by position, for APIs that answer an ordered list of keys.
+func (m *SyncMap[K, V]) GetMulti(keys []K) []V {
+	values := make([]V, len(keys))
+	for i, key := range keys {
+		values[i] = m.Get(key)
+	}
+	return values
+}
+
 // LoadAndDelete deletes the value for a key, returning the previous value if any.
-// It enforces type safety and treats stored nil values as "not found".
-func (m *SyncMap[K, V]) LoadAndDelete(key K) (V, bool) {
+// loaded reports whether an entry existed and was removed, even if it held a stored nil
+// or a value of the wrong type; in that case the zero value of V is returned.
+func (m *SyncMap[K, V]) LoadAndDelete(key K) (value V, loaded bool) {
 	m.lazyInit()
-	value, ok := m.syncMap.LoadAndDelete(key)
-	typedValue, typedOk := value.(V)
-	return typedValue, (typedOk && ok && value != nil)
+	m.mustBeWritable()
+	v, ok := m.loadAndDelete(key)
+	typedV, _ := m.typedValue(v)
+	return typedV, ok
 }
 
 // Store sets the value for a key.
 func (m *SyncMap[K, V]) Store(key K, value V) {
 	m.lazyInit()
-	m.syncMap.Store(key, value)
+	m.mustBeWritable()
+	m.store(key, value)
+}
+
+// StoreThrottled stores value under key only if at least minInterval has passed since the
+// last StoreThrottled write to key, and reports whether it stored. This collapses bursts of
+// updates (e.g. sensor readings) to at most one per interval. Only StoreThrottled writes are
+// timed: plain Store calls neither count as a write here nor are they blocked.
+// Deleting the key forgets its timestamp. The check and store hold the local lock.
+func (m *SyncMap[K, V]) StoreThrottled(key K, value V, minInterval time.Duration) bool {
+	m.lazyInit()
+	m.mustBeWritable()
+	m.core.localLock.Lock()
+	defer m.core.localLock.Unlock()
+	current := now()
+	throttled := m.throttleTimes()
+	if last, ok := throttled.Load(key); ok && current.Sub(last.(time.Time)) < minInterval {
+		return false
+	}
+	throttled.Store(key, current)
+	m.Store(key, value)
+	return true
 }
 
 // LoadOrStore returns the existing value for the key if present.
 // Otherwise, it stores and returns the given value.
+// Presence follows LoadPresent: an existing entry holding a stored nil counts as present,
+// so it is returned with loaded=true and the new value is not stored. If the existing value
+// is nil or not a V, the zero value of V is returned.
 func (m *SyncMap[K, V]) LoadOrStore(key K, value V) (actual V, loaded bool) {
 	m.lazyInit()
-	v, ok := m.syncMap.LoadOrStore(key, value)
-	typedV, typeOk := v.(V)
-	return typedV, ok && typeOk
+	m.mustBeWritable()
+	v, ok := m.loadOrStore(key, value)
+	typedV, _ := m.typedValue(v)
+	return typedV, ok
 }
 
 // Swap stores a new value for a key, and returns the previous value if any.
+// Like LoadAndDelete, loaded reports whether an entry existed, even if the previous value
+// was a stored nil or of the wrong type; in that case the zero value of V is returned.
 func (m *SyncMap[K, V]) Swap(key K, value V) (previous V, loaded bool) {
 	m.lazyInit()
-	v, ok := m.syncMap.Swap(key, value)
-	typedV, typeOk := v.(V)
-	return typedV, ok && typeOk
+	m.mustBeWritable()
+	v, ok := m.swap(key, value)
+	typedV, _ := m.typedValue(v)
+	return typedV, ok
 }
 
 // Delete deletes the value for a key.
 func (m *SyncMap[K, V]) Delete(key K) {
 	m.lazyInit()
-	m.syncMap.Delete(key)
+	m.mustBeWritable()
+	m.loadAndDelete(key)
+}
+
+// Move atomically moves the value stored under oldKey to newKey, overwriting any value
+// already stored under newKey. It returns true if oldKey was present; otherwise it is a no-op.
+// The move holds the local lock, so it is atomic with respect to other composite operations.
+// Lock-free readers may briefly see the value under both keys, but never under neither.
+func (m *SyncMap[K, V]) Move(oldKey, newKey K) bool {
+	m.lazyInit()
+	m.mustBeWritable()
+	m.core.localLock.Lock()
+	defer m.core.localLock.Unlock()
+	value, ok := m.lookup(oldKey)
+	if !ok {
+		return false
+	}
+	if oldKey == newKey {
+		return true
+	}
+	m.Store(newKey, value)
+	m.Delete(oldKey)
+	return true
+}
+
+// RenameKeys recomputes every key with mapFn and moves each entry whose key changed to
+// its new key, returning the number of entries moved. Entries whose key is unchanged are skipped.
+// When several entries map to the same new key, the last one in iteration order wins; since
+// iteration order is unspecified, callers that merge keys should not rely on which value survives.
+// A renamed entry also overwrites an unchanged entry that already used the new key.
+// The whole operation holds the local lock.
+func (m *SyncMap[K, V]) RenameKeys(mapFn func(key K) K) int {
+	m.lazyInit()
+	m.mustBeWritable()
+	m.core.localLock.Lock()
+	defer m.core.localLock.Unlock()
+	var oldKeys, newKeys []K
+	var values []V
+	m.rangeRaw(func(key, value any) bool {
+		typedKey, typedKeyOk := m.typedKey(key)
+		typedValue, typedValueOk := m.typedValue(value)
+		if !typedKeyOk || !typedValueOk {
+			return true
+		}
+		if newKey := mapFn(typedKey); newKey != typedKey {
+			oldKeys = append(oldKeys, typedKey)
+			newKeys = append(newKeys, newKey)
+			values = append(values, typedValue)
+		}
+		return true
+	})
+	for _, key := range oldKeys {
+		m.Delete(key)
+	}
+	for i, key := range newKeys {
+		m.Store(key, values[i])
+	}
+	return len(oldKeys)
+}
+
+// BatchStore stores every entry of entries in the map. It takes the local lock once for the
+// whole batch and updates the size counter in bulk, which is cheaper than calling Store in a
+// loop for large batches. The batch is atomic with respect to other composite operations.
+func (m *SyncMap[K, V]) BatchStore(entries map[K]V) {
+	m.lazyInit()
+	m.mustBeWritable()
+	m.core.localLock.Lock()
+	defer m.core.localLock.Unlock()
+	m.storeBatch(entries)
+}
+
+// UpsertMany writes every entry into the map: absent keys are stored as given, while keys
+// already present are stored as merge(old, new). merge is only called for existing keys.
+// It returns the number of keys affected. The whole batch holds the local lock.
+func (m *SyncMap[K, V]) UpsertMany(entries map[K]V, merge func(old, new V) V) int {
+	m.lazyInit()
+	m.mustBeWritable()
+	m.core.localLock.Lock()
+	defer m.core.localLock.Unlock()
+	for key, value := range entries {
+		if old, ok := m.lookup(key); ok {
+			value = merge(old, value)
+		}
+		m.Store(key, value)
+	}
+	return len(entries)
+}
+
+// ExtractIf deletes every entry for which pred returns true and returns the deleted
+// entries in a plain map. Filtering, deletion and collection happen in a single pass
+// under the local lock, so no composite operation observes a partial extraction.
+func (m *SyncMap[K, V]) ExtractIf(pred func(key K, value V) bool) map[K]V {
+	m.lazyInit()
+	m.mustBeWritable()
+	m.core.localLock.Lock()
+	defer m.core.localLock.Unlock()
+	extracted := make(map[K]V)
+	m.rangeRaw(func(key, value any) bool {
+		typedKey, typedKeyOk := m.typedKey(key)
+		typedValue, typedValueOk := m.typedValue(value)
+		if typedKeyOk && typedValueOk && pred(typedKey, typedValue) {
+			m.loadAndDelete(typedKey)
+			extracted[typedKey] = typedValue
+		}
+		return true
+	})
+	return extracted
+}
+
+// TransformValues replaces every value in the map with fn(key, value), in place.
+// Unlike SyncTransform it does not allocate a new map, so it suits same-type adjustments
+// such as applying a discount to every price. The whole pass runs under the local lock.
+func (m *SyncMap[K, V]) TransformValues(fn func(key K, value V) V) {
+	m.lazyInit()
+	m.mustBeWritable()
+	m.core.localLock.Lock()
+	defer m.core.localLock.Unlock()
+	m.rangeRaw(func(key, value any) bool {
+		typedKey, typedKeyOk := m.typedKey(key)
+		typedValue, typedValueOk := m.typedValue(value)
+		if typedKeyOk && typedValueOk {
+			m.store(typedKey, fn(typedKey, typedValue))
+		}
+		return true
+	})
+}
+
+// DeleteIfValue deletes the entry for key only if it exists and pred reports true for its
+// value, returning the deleted value and whether the deletion happened. It is the single-key
+// form of ExtractIf, for removing an entry only while it is still in an expected state.
+// Presence follows Load. The check and the delete happen under the local lock.
+func (m *SyncMap[K, V]) DeleteIfValue(key K, pred func(value V) bool) (V, bool) {
+	m.lazyInit()
+	m.mustBeWritable()
+	m.core.localLock.Lock()
+	defer m.core.localLock.Unlock()
+	value, ok := m.lookup(key)
+	if !ok || !pred(value) {
+		var zero V
+		return zero, false
+	}
+	m.loadAndDelete(key)
+	return value, true
+}
+
+// AtomicReplace computes a new value for key from the current one and stores it, returning
+// the old value, the new value, and whether the key existed before, for example to emit a
+// before/after audit event. fn receives the current value and whether it was present,
+// following LoadPresent. The read, fn and the store all happen under the local lock.
+func (m *SyncMap[K, V]) AtomicReplace(key K, fn func(old V, loaded bool) V) (old V, new V, existed bool) {
+	m.lazyInit()
+	m.mustBeWritable()
+	m.core.localLock.Lock()
+	defer m.core.localLock.Unlock()
+	old, existed = m.LoadPresent(key)
+	new = fn(old, existed)
+	m.Store(key, new)
+	return old, new, existed
+}
+
+// CompareAndSwapFunc stores new for key only if an entry for key exists and eq reports its
+// current value equal to old, and reports whether the swap happened. It brings
+// compare-and-swap to values that aren't comparable, such as slices or structs holding them.
+// Presence follows LoadPresent. The load, comparison and store happen under the local lock.
+func (m *SyncMap[K, V]) CompareAndSwapFunc(key K, old, new V, eq func(a, b V) bool) bool {
+	m.lazyInit()
+	m.mustBeWritable()
+	m.core.localLock.Lock()
+	defer m.core.localLock.Unlock()
+	current, ok := m.LoadPresent(key)
+	if !ok || !eq(current, old) {
+		return false
+	}
+	m.Store(key, new)
+	return true
 }
 
 // Range calls fn sequentially for each key and value present in the map.
+// Presence follows LoadPresent, like Has and Len: an entry holding a stored nil is visited
+// with a nil value, even though Load reports that key as not found.
 // If fn returns false, the iteration stops.
 // It locks the map locally to prevent concurrent Range/Clear operations.
-// It includes a panic recovery block to ensure a panic in the user-supplied fn does not crash the iteration.
+// A panic in the user-supplied fn is handled according to the map's panic policy; by default
+// it is recovered and logged so that it does not crash the iteration (see SetPanicPolicy).
 func (m *SyncMap[K, V]) Range(fn func(key K, value V) bool) {
 	m.lazyInit()
-	m.localLock.Lock()
-	defer m.localLock.Unlock()
+	m.core.localLock.Lock()
+	defer m.core.localLock.Unlock()
 
 	wrappedFn := func(key, value any) bool {
-		rtrn := true
-		(func() {
-			defer func() {
-				if r := recover(); r != nil {
-					// NOTE: fmt.Printf has been replaced with log.Printf
-					log.Printf("SyncMap Range Panic (Recovered): %+v", r)
-				}
-			}()
-			typedKey, typedKeyOk := key.(K)
-			typedValue, typedValueOk := value.(V)
-			if typedKeyOk && typedValueOk {
-				rtrn = fn(typedKey, typedValue)
-			} else {
-				// NOTE: fmt.Printf has been replaced with log.Printf (using log.Print for simpler output)
-				log.Printf("SyncMap: Range assertion failed for key: %+v", key)
+		typedKey, typedKeyOk := m.typedKey(key)
+		typedValue, typedValueOk := m.typedValue(value)
+		if !typedKeyOk || !typedValueOk {
+			logRangeAssertion(key)
+			return true
+		}
+		return m.callSafely(fn, typedKey, typedValue)
+	}
+	m.rangeRaw(wrappedFn)
+}
+
+// RangeSnapshot calls fn for each key and value in a point-in-time snapshot of the map.
+// The local lock is held only while the snapshot is taken, not while fn runs, so a slow fn
+// doesn't block Clear and other composite operations the way it would under Range.
+// Writes made after the snapshot, including those made by fn, are not reflected.
+// If fn returns false, the iteration stops.
+func (m *SyncMap[K, V]) RangeSnapshot(fn func(key K, value V) bool) {
+	var keys []K
+	var values []V
+	m.Range(func(key K, value V) bool {
+		keys = append(keys, key)
+		values = append(values, value)
+		return true
+	})
+	for i, key := range keys {
+		if !m.callSafely(fn, key, values[i]) {
+			return
+		}
+	}
+}
+
+// Tap calls fn for each key and value in the map, via Range, and returns the map for chaining.
+// It is meant for observing entries mid-pipeline (logging, metrics); fn cannot stop the
+// iteration and Tap does not modify the map.
+func (m *SyncMap[K, V]) Tap(fn func(key K, value V)) *SyncMap[K, V] {
+	m.Range(func(key K, value V) bool {
+		fn(key, value)
+		return true
+	})
+	return m
+}
+
+// RangeErr calls fn sequentially for each key and value present in the map.
+// Iteration stops at the first non-nil error returned by fn, and that error is returned.
+// It returns nil if fn succeeds for every entry. Locking and panic recovery follow Range;
+// a recovered panic is not reported as an error.
+func (m *SyncMap[K, V]) RangeErr(fn func(key K, value V) error) error {
+	var err error
+	m.Range(func(key K, value V) bool {
+		err = fn(key, value)
+		return err == nil
+	})
+	return err
+}
+
+// RangeRandom calls fn for each key and value present in the map, in a random order.
+// The keys are snapshotted and shuffled using r, or the package-level source from
+// math/rand if r is nil. Entries deleted after the snapshot are skipped.
+// If fn returns false, the iteration stops.
+func (m *SyncMap[K, V]) RangeRandom(r *rand.Rand, fn func(key K, value V) bool) {
+	keys := m.AppendKeys(nil)
+	swap := func(i, j int) { keys[i], keys[j] = keys[j], keys[i] }
+	if r != nil {
+		r.Shuffle(len(keys), swap)
+	} else {
+		rand.Shuffle(len(keys), swap)
+	}
+	for _, key := range keys {
+		value, ok := m.LoadPresent(key)
+		if !ok {
+			continue
+		}
+		if !m.callSafely(fn, key, value) {
+			return
+		}
+	}
+}
+
+// Fingerprint combines hashVal over every entry into a single order-independent hash.
+// Per-entry hashes are summed, so the result does not depend on iteration or insertion order.
+// Maps with equal contents always produce equal fingerprints, but different contents can
+// collide; a matching fingerprint means "probably unchanged", so confirm with a full
+// comparison when that matters.
+func (m *SyncMap[K, V]) Fingerprint(hashVal func(key K, value V) uint64) uint64 {
+	var sum uint64
+	m.Range(func(key K, value V) bool {
+		sum += hashVal(key, value)
+		return true
+	})
+	return sum
+}
+
+// CanonicalBytes returns a byte encoding of the map's contents that is identical for maps
+// with identical contents, regardless of insertion order, for hashing or content addressing.
+// encode turns one entry into bytes and must be deterministic. The encoded entries are
+// sorted bytewise and each is written with a uvarint length prefix, so entry boundaries
+// are unambiguous.
+func (m *SyncMap[K, V]) CanonicalBytes(encode func(key K, value V) []byte) []byte {
+	var encoded [][]byte
+	size := 0
+	m.Range(func(key K, value V) bool {
+		entry := encode(key, value)
+		encoded = append(encoded, entry)
+		size += len(entry) + binary.MaxVarintLen64
+		return true
+	})
+	slices.SortFunc(encoded, bytes.Compare)
+	out := make([]byte, 0, size)
+	for _, entry := range encoded {
+		out = binary.AppendUvarint(out, uint64(len(entry)))
+		out = append(out, entry...)
+	}
+	return out
+}
+
+// DistinctValueCount returns the number of distinct values in the map, where eq decides
+// whether two values are the same. It compares each value against every distinct value
+// found so far, so it is O(n²); for comparable values prefer DistinctValues.
+func (m *SyncMap[K, V]) DistinctValueCount(eq func(a, b V) bool) int {
+	var distinct []V
+	m.Range(func(_ K, value V) bool {
+		for _, seen := range distinct {
+			if eq(seen, value) {
+				return true
 			}
-		})()
-		return rtrn
+		}
+		distinct = append(distinct, value)
+		return true
+	})
+	return len(distinct)
+}
+
+// ValuesUnique reports whether no two entries share the same projection key(value), for
+// checking invariants such as "no two users share an email". key must return comparable
+// values. It iterates once and stops at the first duplicate.
+func (m *SyncMap[K, V]) ValuesUnique(key func(value V) any) bool {
+	seen := make(map[any]struct{})
+	unique := true
+	m.Range(func(_ K, value V) bool {
+		projected := key(value)
+		if _, dup := seen[projected]; dup {
+			unique = false
+			return false
+		}
+		seen[projected] = struct{}{}
+		return true
+	})
+	return unique
+}
+
+// RangeByHash calls fn for each key and value present in the map, in ascending order of
+// hash(key). This gives a reproducible order across runs for key types that aren't ordered,
+// as long as hash is deterministic; keys with equal hashes are visited in unspecified order.
+// The keys are snapshotted first; entries deleted after the snapshot are skipped.
+// If fn returns false, the iteration stops.
+func (m *SyncMap[K, V]) RangeByHash(hash func(key K) uint64, fn func(key K, value V) bool) {
+	keys := m.Keys()
+	hashes := make(map[K]uint64, len(keys))
+	for _, key := range keys {
+		hashes[key] = hash(key)
+	}
+	slices.SortFunc(keys, func(a, b K) int {
+		return cmp.Compare(hashes[a], hashes[b])
+	})
+	for _, key := range keys {
+		value, ok := m.LoadPresent(key)
+		if !ok {
+			continue
+		}
+		if !m.callSafely(fn, key, value) {
+			return
+		}
+	}
+}
+
+// RangeSample calls fn for a random sample of the map's entries, visiting each entry
+// independently with probability fraction: 1 visits every entry and 0 visits none.
+// Random numbers come from r, or the package-level source from math/rand if r is nil.
+// Aggregates computed over the sample are approximate. Locking, panic handling and
+// early stopping follow Range.
+func (m *SyncMap[K, V]) RangeSample(fraction float64, r *rand.Rand, fn func(key K, value V) bool) {
+	if fraction <= 0 {
+		return
 	}
-	m.syncMap.Range(wrappedFn)
+	m.Range(func(key K, value V) bool {
+		if fraction < 1 {
+			var p float64
+			if r != nil {
+				p = r.Float64()
+			} else {
+				p = rand.Float64()
+			}
+			if p >= fraction {
+				return true
+			}
+		}
+		return fn(key, value)
+	})
 }
 
 // ToMap copies all key/value pairs into a standard Go map.
+// Like Range, it includes entries holding a stored nil.
 func (m *SyncMap[K, V]) ToMap() map[K]V {
 	mp := make(map[K]V)
 	m.Range(func(key K, value V) bool {
@@ -199,6 +912,106 @@ func (m *SyncMap[K, V]) ToMap() map[K]V {
 	return mp
 }
 
+// snapshot copies the map's entries into a plain map without taking the local lock.
+// Callers that need a consistent view must hold the lock themselves.
+func (m *SyncMap[K, V]) snapshot() map[K]V {
+	mp := make(map[K]V)
+	m.rangeRaw(func(key, value any) bool {
+		typedKey, typedKeyOk := m.typedKey(key)
+		typedValue, typedValueOk := m.typedValue(value)
+		if typedKeyOk && typedValueOk {
+			mp[typedKey] = typedValue
+		}
+		return true
+	})
+	return mp
+}
+
+// Len returns the number of entries in the map.
+// It reads an atomic counter maintained by every write, so it is O(1).
+// Forked maps are the exception: their entries are merged with the parent's and counted in O(n).
+func (m *SyncMap[K, V]) Len() int {
+	m.lazyInit()
+	return m.count()
+}
+
+// IsEmpty reports whether the map has no entries.
+func (m *SyncMap[K, V]) IsEmpty() bool {
+	return m.Len() == 0
+}
+
+// Stats is a point-in-time summary of a SyncMap, returned by DebugStats.
+type Stats struct {
+	// Entries is the number of entries in the map.
+	Entries int
+	// DistinctValues is the number of distinct values, or -1 if the values aren't comparable.
+	DistinctValues int
+	// TypeMismatches is the value of TypeMismatchCount.
+	TypeMismatches uint64
+	// Lookups is the number of lookups counted while TrackAccess is on, and LookedUpKeys
+	// the number of distinct keys they asked for. Both are 0 while tracking is off.
+	Lookups      uint64
+	LookedUpKeys int
+}
+
+// DebugStats returns a snapshot of the map's statistics for dashboards and debugging.
+// Counting distinct values walks the map once, so it costs O(n) like RangeSnapshot.
+func (m *SyncMap[K, V]) DebugStats() Stats {
+	stats := Stats{
+		Entries:        m.Len(),
+		DistinctValues: -1,
+		TypeMismatches: m.TypeMismatchCount(),
+	}
+	seen := make(map[any]struct{})
+	comparable := true
+	m.Range(func(_ K, value V) bool {
+		if v := any(value); v != nil && !reflect.ValueOf(v).Comparable() {
+			comparable = false
+			return false
+		}
+		seen[any(value)] = struct{}{}
+		return true
+	})
+	if comparable {
+		stats.DistinctValues = len(seen)
+	}
+	stats.Lookups, stats.LookedUpKeys = m.accessTotals()
+	return stats
+}
+
+// Keys returns all keys in the map as a slice, in unspecified order.
+// Like Range, it includes keys holding a stored nil.
+func (m *SyncMap[K, V]) Keys() []K {
+	return m.AppendKeys(nil)
+}
+
+// Values returns all values in the map as a slice, in unspecified order.
+func (m *SyncMap[K, V]) Values() []V {
+	return m.AppendValues(nil)
+}
+
+// AppendKeys appends all keys in the map to dst and returns the extended slice.
+// Like the built-in append, dst may be nil; passing a reused buffer (e.g. buf[:0])
+// avoids allocating a new slice on every call.
+func (m *SyncMap[K, V]) AppendKeys(dst []K) []K {
+	m.Range(func(key K, _ V) bool {
+		dst = append(dst, key)
+		return true
+	})
+	return dst
+}
+
+// AppendValues appends all values in the map to dst and returns the extended slice.
+// Like the built-in append, dst may be nil; passing a reused buffer (e.g. buf[:0])
+// avoids allocating a new slice on every call.
+func (m *SyncMap[K, V]) AppendValues(dst []V) []V {
+	m.Range(func(_ K, value V) bool {
+		dst = append(dst, value)
+		return true
+	})
+	return dst
+}
+
 // SyncTransform creates a new SyncMap by applying a transformation function to all
 // elements of the current map.
 func SyncTransform[K1, K2 comparable, V1, V2 any](m1 SyncMap[K1, V1], fn func(key K1, value V1) (K2, V2)) SyncMap[K2, V2] {
@@ -211,6 +1024,31 @@ func SyncTransform[K1, K2 comparable, V1, V2 any](m1 SyncMap[K1, V1], fn func(ke
 	return m2
 }
 
+// MapKeys creates a new SyncMap with every entry rekeyed by fn and its value unchanged,
+// for example to normalize string keys. When several entries map to the same new key,
+// the last one in Range order wins; since that order is unspecified, so is the survivor.
+// The result is independent of m.
+func MapKeys[K1, K2 comparable, V any](m SyncMap[K1, V], fn func(key K1, value V) K2) SyncMap[K2, V] {
+	return SyncTransform(m, func(key K1, value V) (K2, V) {
+		return fn(key, value), value
+	})
+}
+
+// FilterMap creates a new SyncMap from the entries of m for which fn reports keep, storing the
+// key and value fn returns for each. It filters and transforms in a single Range, where
+// SyncTransform alone cannot drop entries. As with MapKeys, when several kept entries map to
+// the same key the survivor is unspecified. The result is independent of m.
+func FilterMap[K comparable, V1, V2 any](m SyncMap[K, V1], fn func(key K, value V1) (K, V2, bool)) SyncMap[K, V2] {
+	m2 := NewSyncMap[K, V2]()
+	m.Range(func(key K, value V1) bool {
+		if k2, v2, keep := fn(key, value); keep {
+			m2.Store(k2, v2)
+		}
+		return true
+	})
+	return m2
+}
+
 // Copy returns a shallow copy of the SyncMap.
 func (m *SyncMap[K, V]) Copy() SyncMap[K, V] {
 	return SyncTransform(*m, func(k K, v V) (K, V) { return k, v })
@@ -229,3 +1067,65 @@ func Merge[K comparable, V any](a, b SyncMap[K, V]) SyncMap[K, V] {
 	})
 	return out
 }
+
+// MergeReduce folds all maps into a new SyncMap, using combine to merge the values of keys
+// present in more than one map; combine receives the accumulated value first. This is the
+// "shuffle and combine" step when joining partial results, such as per-worker counts.
+// A single map yields a copy of it, and an empty list yields an empty map.
+func MergeReduce[K comparable, V any](maps []SyncMap[K, V], combine func(a, b V) V) SyncMap[K, V] {
+	out := NewSyncMap[K, V]()
+	for _, m := range maps {
+		m.Range(func(k K, v V) bool {
+			if acc, ok := out.Load(k); ok {
+				v = combine(acc, v)
+			}
+			out.Store(k, v)
+			return true
+		})
+	}
+	return out
+}
+
+// DistinctValues returns the number of distinct values in the map, using a set, in O(n).
+func DistinctValues[K comparable, V comparable](m SyncMap[K, V]) int {
+	seen := make(map[V]struct{})
+	m.Range(func(_ K, value V) bool {
+		seen[value] = struct{}{}
+		return true
+	})
+	return len(seen)
+}
+
+// ReduceOrdered folds the map into a single value, visiting entries in ascending key order.
+// It snapshots and sorts the keys first, trading a sort for a deterministic result when fn
+// is not commutative (for example, when building an ordered string).
+func ReduceOrdered[K cmp.Ordered, V any, A any](m SyncMap[K, V], init A, fn func(acc A, key K, value V) A) A {
+	snapshot := m.ToMap()
+	keys := make([]K, 0, len(snapshot))
+	for key := range snapshot {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	acc := init
+	for _, key := range keys {
+		acc = fn(acc, key, snapshot[key])
+	}
+	return acc
+}
+
+// Collect builds a result container from the map's entries by threading init through add
+// for each entry, for example to fill a slice, a strings.Builder or a custom struct.
+// Entries are visited in unspecified order; sort afterwards if order matters:
+//
+//	keys := asyncmap.Collect(m, []string(nil), func(keys []string, k string, _ int) []string {
+//		return append(keys, k)
+//	})
+//	slices.Sort(keys)
+func Collect[K comparable, V any, R any](m SyncMap[K, V], init R, add func(acc R, key K, value V) R) R {
+	acc := init
+	m.Range(func(key K, value V) bool {
+		acc = add(acc, key, value)
+		return true
+	})
+	return acc
+}
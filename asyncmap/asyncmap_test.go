@@ -0,0 +1,1051 @@
+package asyncmap
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"log"
+	"maps"
+	"math/rand"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestNewSyncMapAllocs(t *testing.T) {
+	allocs := testing.AllocsPerRun(100, func() {
+		m := NewSyncMap[string, int]()
+		_ = m
+	})
+	if allocs > 2 {
+		t.Fatalf("NewSyncMap allocs = %v, want at most 2", allocs)
+	}
+}
+
+func BenchmarkNew(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		m := NewSyncMap[string, int]()
+		_ = m
+	}
+}
+
+func TestStoreThrottledForgetsDeletedKeys(t *testing.T) {
+	m := NewSyncMap[string, int]()
+	if !m.StoreThrottled("k", 1, time.Hour) {
+		t.Fatal("first StoreThrottled was refused")
+	}
+	if m.StoreThrottled("k", 2, time.Hour) {
+		t.Fatal("StoreThrottled within the interval was accepted")
+	}
+	m.Delete("k")
+	if !m.StoreThrottled("k", 3, time.Hour) {
+		t.Fatal("StoreThrottled after Delete was refused")
+	}
+	if got := m.Get("k"); got != 3 {
+		t.Fatalf("Get = %d, want 3", got)
+	}
+	m.Reset()
+	if !m.StoreThrottled("k", 4, time.Hour) {
+		t.Fatal("StoreThrottled after Reset was refused")
+	}
+}
+
+// fakeClock replaces now for the duration of a test and returns a function advancing it.
+func fakeClock(t *testing.T) (advance func(time.Duration)) {
+	current := time.Unix(1_000_000, 0)
+	now = func() time.Time { return current }
+	t.Cleanup(func() { now = time.Now })
+	return func(d time.Duration) { current = current.Add(d) }
+}
+
+func TestStoreThrottledInterval(t *testing.T) {
+	advance := fakeClock(t)
+	m := NewSyncMap[string, int]()
+	if !m.StoreThrottled("k", 1, time.Second) {
+		t.Fatal("first StoreThrottled was refused")
+	}
+	advance(500 * time.Millisecond)
+	if m.StoreThrottled("k", 2, time.Second) {
+		t.Fatal("StoreThrottled halfway through the interval was accepted")
+	}
+	advance(499 * time.Millisecond)
+	if m.StoreThrottled("k", 3, time.Second) {
+		t.Fatal("StoreThrottled just under the interval was accepted")
+	}
+	if got := m.Get("k"); got != 1 {
+		t.Fatalf("Get = %d after dropped writes, want 1", got)
+	}
+	advance(2 * time.Millisecond)
+	if !m.StoreThrottled("k", 4, time.Second) {
+		t.Fatal("StoreThrottled just past the interval was refused")
+	}
+	if got := m.Get("k"); got != 4 {
+		t.Fatalf("Get = %d, want 4", got)
+	}
+	if m.StoreThrottled("k", 5, time.Second) {
+		t.Fatal("the accepted write did not restart the interval")
+	}
+}
+
+func TestSwapContents(t *testing.T) {
+	a := NewSyncMap(map[string]int{"a": 1})
+	b := NewSyncMap(map[string]int{"b": 2, "c": 3})
+	SwapContents(&a, &b)
+	if a.Len() != 2 || !a.Has("b") || b.Len() != 1 || !b.Has("a") {
+		t.Fatalf("after swap a=%v b=%v", a.ToMap(), b.ToMap())
+	}
+}
+
+func TestSwapContentsRejectsForks(t *testing.T) {
+	parent := NewSyncMap(map[string]int{"x": 1})
+	child := parent.Fork()
+	child.Delete("x")
+	plain := NewSyncMap[string, int]()
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Fatal("SwapContents with a fork did not panic")
+			}
+		}()
+		SwapContents(child, &plain)
+	}()
+	if actual, loaded := plain.LoadOrStore("x", 42); loaded || actual != 42 {
+		t.Fatalf("LoadOrStore = (%d, %v), want (42, false)", actual, loaded)
+	}
+	plain.Delete("x")
+	if plain.Len() != 0 {
+		t.Fatalf("Len = %d, want 0", plain.Len())
+	}
+	if child.Has("x") {
+		t.Fatal("fork lost its delete")
+	}
+}
+
+func BenchmarkResetRefill(b *testing.B) {
+	b.ReportAllocs()
+	m := NewSyncMap[int, int]()
+	for i := 0; i < b.N; i++ {
+		for j := 0; j < 100; j++ {
+			m.Store(j, j)
+		}
+		m.Reset()
+	}
+}
+
+func BenchmarkNewMapRefill(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		m := NewSyncMap[int, int]()
+		for j := 0; j < 100; j++ {
+			m.Store(j, j)
+		}
+	}
+}
+
+func BenchmarkClearRefill(b *testing.B) {
+	b.ReportAllocs()
+	m := NewSyncMap[int, int]()
+	for i := 0; i < b.N; i++ {
+		for j := 0; j < 100; j++ {
+			m.Store(j, j)
+		}
+		m.Clear()
+	}
+}
+
+func TestSwapAndRangeLosesNoIncrements(t *testing.T) {
+	m := NewSyncMap[int, int]()
+	const writers, perWriter = 4, 1000
+	var wg sync.WaitGroup
+	for w := 0; w < writers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < perWriter; i++ {
+				FetchAdd(&m, i%7, 1)
+			}
+		}()
+	}
+	total := 0
+	flush := func() {
+		m.SwapAndRange(func(_ int, count int) { total += count })
+	}
+	for i := 0; i < 50; i++ {
+		flush()
+	}
+	wg.Wait()
+	flush()
+	if total != writers*perWriter {
+		t.Fatalf("flushed %d increments, want %d", total, writers*perWriter)
+	}
+}
+
+func TestRotateWindowsPartitionWrites(t *testing.T) {
+	m := NewSyncMap[int, int]()
+	const writers, perWriter = 4, 1000
+	var wg sync.WaitGroup
+	for w := 0; w < writers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < perWriter; i++ {
+				FetchAdd(&m, w*perWriter+i, 1)
+			}
+		}()
+	}
+	var windows []*SyncMap[int, int]
+	for i := 0; i < 50; i++ {
+		windows = append(windows, m.Rotate())
+	}
+	wg.Wait()
+	windows = append(windows, m.Rotate(), &m)
+	seen := make(map[int]int)
+	total := 0
+	for i, window := range windows {
+		window.Range(func(key, count int) bool {
+			if prev, dup := seen[key]; dup {
+				t.Errorf("key %d is in windows %d and %d", key, prev, i)
+			}
+			seen[key] = i
+			total += count
+			return true
+		})
+	}
+	if total != writers*perWriter || len(seen) != writers*perWriter {
+		t.Fatalf("windows hold %d writes over %d keys, want %d of each", total, len(seen), writers*perWriter)
+	}
+}
+
+func TestStoredNilIsAnEntry(t *testing.T) {
+	var logs bytes.Buffer
+	log.SetOutput(&logs)
+	defer log.SetOutput(os.Stderr)
+
+	m := NewSyncMap[string, error]()
+	m.Store("ok", nil)
+	if _, ok := m.Load("ok"); ok {
+		t.Fatal("Load reported a stored nil as found")
+	}
+	if _, ok := m.LoadPresent("ok"); !ok || !m.Has("ok") {
+		t.Fatal("stored nil not reported as present")
+	}
+	if _, ok := m.LoadPresent("missing"); ok || m.Has("missing") {
+		t.Fatal("absent key reported as present")
+	}
+	if m.Len() != 1 || len(m.Keys()) != 1 || len(m.ToMap()) != 1 || m.IsEmpty() {
+		t.Fatalf("Len = %d, Keys = %v, ToMap = %v", m.Len(), m.Keys(), m.ToMap())
+	}
+	visited := 0
+	m.Range(func(key string, value error) bool {
+		visited++
+		if key != "ok" || value != nil {
+			t.Fatalf("Range visited (%q, %v)", key, value)
+		}
+		return true
+	})
+	if visited != 1 {
+		t.Fatalf("Range visited %d entries, want 1", visited)
+	}
+	if logs.Len() != 0 || m.TypeMismatchCount() != 0 {
+		t.Fatalf("stored nil treated as a type error: %q", logs.String())
+	}
+}
+
+func TestRangeAgreesWithLoadAndLoadPresent(t *testing.T) {
+	m := NewSyncMap[string, error]()
+	m.Store("err", io.EOF)
+	m.Store("nil", nil)
+	visited := make(map[string]error)
+	m.Range(func(key string, value error) bool {
+		visited[key] = value
+		return true
+	})
+	if len(visited) != m.Len() {
+		t.Fatalf("Range visited %d entries, Len = %d", len(visited), m.Len())
+	}
+	for key, value := range visited {
+		present, ok := m.LoadPresent(key)
+		if !ok || present != value {
+			t.Fatalf("Range visited (%q, %v) but LoadPresent = (%v, %v)", key, value, present, ok)
+		}
+		loaded, found := m.Load(key)
+		if found != (value != nil) || loaded != value {
+			t.Fatalf("Range visited (%q, %v) but Load = (%v, %v)", key, value, loaded, found)
+		}
+	}
+}
+
+func TestDebugStats(t *testing.T) {
+	m := NewSyncMap(map[string]int{"a": 1, "b": 1, "c": 2})
+	m.TrackAccess(true)
+	m.Load("a")
+	m.Get("a")
+	m.GetOrDefault("b", 0)
+	m.Load("missing")
+
+	want := Stats{Entries: 3, DistinctValues: 2, Lookups: 4, LookedUpKeys: 3}
+	if stats := m.DebugStats(); stats != want {
+		t.Fatalf("DebugStats = %+v, want %+v", stats, want)
+	}
+
+	slices := NewSyncMap(map[string][]int{"a": {1}})
+	if got := slices.DebugStats().DistinctValues; got != -1 {
+		t.Fatalf("DistinctValues for slice values = %d, want -1", got)
+	}
+}
+
+func TestStoredNilVisitedByKeyOrderedIteration(t *testing.T) {
+	m := NewSyncMap[string, error]()
+	m.Store("nil", nil)
+	m.Store("err", io.EOF)
+	visits := 0
+	m.RangeByHash(func(key string) uint64 { return uint64(len(key)) }, func(string, error) bool {
+		visits++
+		return true
+	})
+	if visits != 2 {
+		t.Fatalf("RangeByHash visited %d entries, want 2", visits)
+	}
+	if entries, _ := m.Page("", 10); len(entries) != 2 {
+		t.Fatalf("Page returned %d entries, want 2", len(entries))
+	}
+}
+
+func TestRangeErrStopsAtFirstError(t *testing.T) {
+	m := NewSyncMap(map[string]int{"a": 1, "b": 2, "c": 3})
+	errStop := errors.New("stop")
+	calls := 0
+	err := m.RangeErr(func(string, int) error {
+		calls++
+		return errStop
+	})
+	if err != errStop || calls != 1 {
+		t.Fatalf("RangeErr = %v after %d calls, want errStop after 1", err, calls)
+	}
+}
+
+func TestRangeErrVisitsAllOnSuccess(t *testing.T) {
+	m := NewSyncMap(map[string]int{"a": 1, "b": 2, "c": 3})
+	sum := 0
+	err := m.RangeErr(func(_ string, value int) error {
+		sum += value
+		return nil
+	})
+	if err != nil || sum != 6 {
+		t.Fatalf("RangeErr = %v with sum %d, want nil and 6", err, sum)
+	}
+}
+
+func TestAppendKeysReusesBuffer(t *testing.T) {
+	m := NewSyncMap(map[string]int{"a": 1, "b": 2, "c": 3})
+	buf := make([]string, 0, 8)
+	keys := m.AppendKeys(buf[:0])
+	if &keys[0] != &buf[:1][0] {
+		t.Fatal("AppendKeys reallocated a buffer with enough capacity")
+	}
+	slices.Sort(keys)
+	if !slices.Equal(keys, []string{"a", "b", "c"}) {
+		t.Fatalf("AppendKeys = %v", keys)
+	}
+	keys = m.AppendKeys(keys[:1])
+	if len(keys) != 4 || keys[0] != "a" {
+		t.Fatalf("AppendKeys did not keep the existing prefix: %v", keys)
+	}
+}
+
+func TestAppendValuesReusesBuffer(t *testing.T) {
+	m := NewSyncMap(map[string]int{"a": 1, "b": 2, "c": 3})
+	buf := []int{9, 0, 0, 0, 0}
+	values := m.AppendValues(buf[:1])
+	if &values[0] != &buf[0] {
+		t.Fatal("AppendValues reallocated a buffer with enough capacity")
+	}
+	slices.Sort(values[1:])
+	if !slices.Equal(values, []int{9, 1, 2, 3}) {
+		t.Fatalf("AppendValues = %v", values)
+	}
+}
+
+func TestGetAllMissingKeepsOrder(t *testing.T) {
+	m := NewSyncMap(map[string]int{"b": 2, "d": 4})
+	found, missing := m.GetAll("e", "b", "a", "d", "c")
+	if len(found) != 2 || found["b"] != 2 || found["d"] != 4 {
+		t.Fatalf("found = %v", found)
+	}
+	if !slices.Equal(missing, []string{"e", "a", "c"}) {
+		t.Fatalf("missing = %v, want [e a c]", missing)
+	}
+	found, missing = m.GetAll()
+	if found == nil || missing != nil {
+		t.Fatalf("GetAll() = %v, %v; want empty non-nil map and nil slice", found, missing)
+	}
+}
+
+func TestRangeRandomOrderDependsOnSeed(t *testing.T) {
+	m := NewSyncMap[int, int]()
+	for i := 0; i < 32; i++ {
+		m.Store(i, i)
+	}
+	order := func(seed int64) []int {
+		var keys []int
+		m.RangeRandom(rand.New(rand.NewSource(seed)), func(key, _ int) bool {
+			keys = append(keys, key)
+			return true
+		})
+		return keys
+	}
+	first, again, other := order(1), order(1), order(2)
+	if len(first) != 32 {
+		t.Fatalf("RangeRandom visited %d entries, want 32", len(first))
+	}
+	if !slices.Equal(first, again) {
+		t.Fatal("the same seed gave different orders")
+	}
+	if slices.Equal(first, other) {
+		t.Fatal("different seeds gave the same order")
+	}
+}
+
+func TestRangeRandomStopsEarly(t *testing.T) {
+	m := NewSyncMap(map[int]int{1: 1, 2: 2, 3: 3, 4: 4})
+	calls := 0
+	m.RangeRandom(nil, func(int, int) bool {
+		calls++
+		return calls < 2
+	})
+	if calls != 2 {
+		t.Fatalf("RangeRandom made %d calls after returning false, want 2", calls)
+	}
+}
+
+// mustPanic fails the test unless fn panics.
+func mustPanic(t *testing.T, name string, fn func()) {
+	t.Helper()
+	defer func() {
+		if recover() == nil {
+			t.Errorf("%s did not panic", name)
+		}
+	}()
+	fn()
+}
+
+func TestFreezeRejectsWritesButAllowsReads(t *testing.T) {
+	m := NewSyncMap(map[string]int{"a": 1})
+	m.Freeze()
+	if !m.IsFrozen() {
+		t.Fatal("IsFrozen = false after Freeze")
+	}
+	mustPanic(t, "Store", func() { m.Store("b", 2) })
+	mustPanic(t, "Delete", func() { m.Delete("a") })
+	mustPanic(t, "LoadOrStore", func() { m.LoadOrStore("b", 2) })
+	mustPanic(t, "Clear", func() { m.Clear() })
+	mustPanic(t, "BatchStore", func() { m.BatchStore(map[string]int{"b": 2}) })
+	if got := m.Get("a"); got != 1 || m.Len() != 1 || m.Has("b") {
+		t.Fatalf("reads after Freeze: Get=%d Len=%d Has(b)=%v", got, m.Len(), m.Has("b"))
+	}
+}
+
+func TestMoveConcurrentNeverLosesValue(t *testing.T) {
+	m := NewSyncMap(map[string]int{"a": 7})
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			from, to := "a", "b"
+			if g%2 == 1 {
+				from, to = to, from
+			}
+			for i := 0; i < 500; i++ {
+				m.Move(from, to)
+			}
+		}()
+	}
+	wg.Wait()
+	if m.Len() != 1 {
+		t.Fatalf("Len = %d after concurrent moves, want 1: %v", m.Len(), m.ToMap())
+	}
+	for _, value := range m.ToMap() {
+		if value != 7 {
+			t.Fatalf("moved value = %d, want 7", value)
+		}
+	}
+}
+
+func TestRenameKeysCollision(t *testing.T) {
+	m := NewSyncMap(map[string]int{"A": 1, "a": 2, "B": 3, "x": 4})
+	lower := map[string]string{"A": "a", "B": "b"}
+	moved := m.RenameKeys(func(key string) string {
+		if to, ok := lower[key]; ok {
+			return to
+		}
+		return key
+	})
+	if moved != 2 {
+		t.Fatalf("RenameKeys moved %d entries, want 2", moved)
+	}
+	if want := map[string]int{"a": 1, "b": 3, "x": 4}; !maps.Equal(m.ToMap(), want) {
+		t.Fatalf("after RenameKeys = %v, want %v", m.ToMap(), want)
+	}
+}
+
+func TestFingerprintIgnoresInsertionOrder(t *testing.T) {
+	hash := func(key string, value int) uint64 {
+		h := uint64(14695981039346656037)
+		for _, c := range []byte(key) {
+			h = (h ^ uint64(c)) * 1099511628211
+		}
+		return h ^ uint64(value)*0x9e3779b97f4a7c15
+	}
+	a := NewSyncMap[string, int]()
+	b := NewSyncMap[string, int]()
+	keys := []string{"a", "b", "c", "d", "e"}
+	for i, key := range keys {
+		a.Store(key, i)
+	}
+	for i := len(keys) - 1; i >= 0; i-- {
+		b.Store(keys[i], i)
+	}
+	if a.Fingerprint(hash) != b.Fingerprint(hash) {
+		t.Fatal("equal contents inserted in different orders have different fingerprints")
+	}
+	b.Store("c", 99)
+	if a.Fingerprint(hash) == b.Fingerprint(hash) {
+		t.Fatal("changed contents kept the same fingerprint")
+	}
+}
+
+func TestUpsertManyMergesOnlyExistingKeys(t *testing.T) {
+	m := NewSyncMap(map[string]int{"a": 1, "b": 2})
+	var merged []string
+	n := m.UpsertMany(map[string]int{"a": 10, "c": 30}, func(old, new int) int {
+		merged = append(merged, "called")
+		return old + new
+	})
+	if n != 2 || len(merged) != 1 {
+		t.Fatalf("UpsertMany = %d with %d merge calls, want 2 and 1", n, len(merged))
+	}
+	if want := map[string]int{"a": 11, "b": 2, "c": 30}; !maps.Equal(m.ToMap(), want) {
+		t.Fatalf("after UpsertMany = %v, want %v", m.ToMap(), want)
+	}
+}
+
+func TestReduceOrderedFoldsInKeyOrder(t *testing.T) {
+	m := NewSyncMap(map[string]string{"c": "3", "a": "1", "b": "2"})
+	got := ReduceOrdered(m, "", func(acc, key, value string) string {
+		return acc + key + value
+	})
+	if got != "a1b2c3" {
+		t.Fatalf("ReduceOrdered = %q, want %q", got, "a1b2c3")
+	}
+}
+
+func TestExtractIf(t *testing.T) {
+	m := NewSyncMap(map[string]int{"a": 1, "b": 2, "c": 3, "d": 4})
+	even := m.ExtractIf(func(_ string, value int) bool { return value%2 == 0 })
+	if want := map[string]int{"b": 2, "d": 4}; !maps.Equal(even, want) {
+		t.Fatalf("ExtractIf = %v, want %v", even, want)
+	}
+	if want := map[string]int{"a": 1, "c": 3}; !maps.Equal(m.ToMap(), want) || m.Len() != 2 {
+		t.Fatalf("left %v (Len %d), want %v", m.ToMap(), m.Len(), want)
+	}
+	if none := m.ExtractIf(func(string, int) bool { return false }); none == nil || len(none) != 0 {
+		t.Fatalf("ExtractIf with no matches = %v, want an empty map", none)
+	}
+}
+
+func TestTeeMirrorsWrites(t *testing.T) {
+	m := NewSyncMap(map[string]int{"old": 1})
+	replica := NewSyncMap[string, int]()
+	m.Tee(&replica)
+	m.Store("a", 1)
+	m.Store("b", 2)
+	m.Delete("a")
+	m.BatchStore(map[string]int{"c": 3})
+	FetchAdd(&m, "b", 5)
+	if want := map[string]int{"b": 7, "c": 3}; !maps.Equal(replica.ToMap(), want) {
+		t.Fatalf("replica = %v, want %v (existing entries not copied)", replica.ToMap(), want)
+	}
+}
+
+func TestTypeMismatchCountsForeignEntries(t *testing.T) {
+	m := NewSyncMap(map[string]int{"a": 1})
+	raw := m.Unwrap()
+	raw.Store("b", "not an int")
+	raw.Store(42, 2)
+	raw.Store("nil", nil)
+	if got := m.TypeMismatchCount(); got != 0 {
+		t.Fatalf("TypeMismatchCount = %d before any read, want 0", got)
+	}
+	if _, ok := m.Load("b"); ok {
+		t.Fatal("Load returned a wrong-typed value")
+	}
+	if got := m.TypeMismatchCount(); got != 1 {
+		t.Fatalf("TypeMismatchCount = %d after Load, want 1", got)
+	}
+	m.Range(func(string, int) bool { return true })
+	if got := m.TypeMismatchCount(); got != 3 {
+		t.Fatalf("TypeMismatchCount = %d after Range, want 3 (stored nil not counted)", got)
+	}
+}
+
+func TestBatchStoreCountsOverlapOnce(t *testing.T) {
+	m := NewSyncMap(map[int]int{1: 1, 2: 2})
+	m.BatchStore(map[int]int{2: 20, 3: 30, 4: 40})
+	if m.Len() != 4 {
+		t.Fatalf("Len = %d after overlapping BatchStore, want 4", m.Len())
+	}
+	if want := map[int]int{1: 1, 2: 20, 3: 30, 4: 40}; !maps.Equal(m.ToMap(), want) {
+		t.Fatalf("after BatchStore = %v, want %v", m.ToMap(), want)
+	}
+	m.BatchStore(map[int]int{1: 0, 4: 0})
+	if m.Len() != 4 {
+		t.Fatalf("Len = %d after BatchStore of existing keys, want 4", m.Len())
+	}
+}
+
+// batchEntries is the workload for the BatchStore benchmarks.
+func batchEntries() map[int]int {
+	entries := make(map[int]int, 1000)
+	for i := 0; i < 1000; i++ {
+		entries[i] = i
+	}
+	return entries
+}
+
+func BenchmarkBatchStore(b *testing.B) {
+	entries := batchEntries()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		m := NewSyncMap[int, int]()
+		m.BatchStore(entries)
+	}
+}
+
+func BenchmarkStoreLoop(b *testing.B) {
+	entries := batchEntries()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		m := NewSyncMap[int, int]()
+		for key, value := range entries {
+			m.Store(key, value)
+		}
+	}
+}
+
+func TestLoadOrStoreAfterStoredNil(t *testing.T) {
+	m := NewSyncMap[string, *int]()
+	m.Store("k", nil)
+	one := 1
+	actual, loaded := m.LoadOrStore("k", &one)
+	if !loaded || actual != nil {
+		t.Fatalf("LoadOrStore = (%v, %v), want the stored nil with loaded=true", actual, loaded)
+	}
+	if _, ok := m.LoadPresent("k"); !ok || m.Get("k") != nil {
+		t.Fatal("LoadOrStore replaced a stored nil")
+	}
+	m.Delete("k")
+	if actual, loaded := m.LoadOrStore("k", &one); loaded || actual != &one {
+		t.Fatalf("LoadOrStore after Delete = (%v, %v), want (&one, false)", actual, loaded)
+	}
+}
+
+func TestAtomicReplace(t *testing.T) {
+	m := NewSyncMap(map[string]int{"a": 1})
+	inc := func(old int, loaded bool) int {
+		if !loaded {
+			return 100
+		}
+		return old + 1
+	}
+	if old, new, existed := m.AtomicReplace("a", inc); old != 1 || new != 2 || !existed {
+		t.Fatalf("AtomicReplace(present) = (%d, %d, %v), want (1, 2, true)", old, new, existed)
+	}
+	if old, new, existed := m.AtomicReplace("b", inc); old != 0 || new != 100 || existed {
+		t.Fatalf("AtomicReplace(absent) = (%d, %d, %v), want (0, 100, false)", old, new, existed)
+	}
+	if want := map[string]int{"a": 2, "b": 100}; !maps.Equal(m.ToMap(), want) {
+		t.Fatalf("after AtomicReplace = %v, want %v", m.ToMap(), want)
+	}
+}
+
+func TestMapKeysBijection(t *testing.T) {
+	m := NewSyncMap(map[string]int{"a": 1, "b": 2})
+	upper := MapKeys(m, func(key string, _ int) string { return strings.ToUpper(key) })
+	if want := map[string]int{"A": 1, "B": 2}; !maps.Equal(upper.ToMap(), want) {
+		t.Fatalf("MapKeys = %v, want %v", upper.ToMap(), want)
+	}
+	upper.Store("C", 3)
+	if m.Len() != 2 {
+		t.Fatal("writing to the MapKeys result changed the source")
+	}
+}
+
+func TestMapKeysCollision(t *testing.T) {
+	m := NewSyncMap(map[string]int{"a": 1, "A": 2, "b": 3})
+	lower := MapKeys(m, func(key string, _ int) string { return strings.ToLower(key) })
+	if lower.Len() != 2 || lower.Get("b") != 3 {
+		t.Fatalf("MapKeys = %v, want two keys with b:3", lower.ToMap())
+	}
+	if got := lower.Get("a"); got != 1 && got != 2 {
+		t.Fatalf("colliding key a = %d, want one of the source values", got)
+	}
+}
+
+func TestRangeSnapshotDoesNotBlockClear(t *testing.T) {
+	m := NewSyncMap(map[string]int{"a": 1, "b": 2})
+	visited := 0
+	m.RangeSnapshot(func(string, int) bool {
+		visited++
+		if visited == 1 {
+			cleared := make(chan struct{})
+			go func() {
+				m.Clear()
+				close(cleared)
+			}()
+			select {
+			case <-cleared:
+			case <-time.After(5 * time.Second):
+				t.Fatal("Clear blocked while the RangeSnapshot callback was running")
+			}
+		}
+		return true
+	})
+	if visited != 2 || m.Len() != 0 {
+		t.Fatalf("visited %d snapshot entries with Len %d after Clear, want 2 and 0", visited, m.Len())
+	}
+}
+
+func TestLoadAndDeleteNilAndWrongTyped(t *testing.T) {
+	m := NewSyncMap[string, *int]()
+	m.Store("nil", nil)
+	m.Unwrap().Store("wrong", "not a *int")
+	for _, key := range []string{"nil", "wrong"} {
+		if value, loaded := m.LoadAndDelete(key); !loaded || value != nil {
+			t.Fatalf("LoadAndDelete(%q) = (%v, %v), want (nil, true)", key, value, loaded)
+		}
+		if _, ok := m.Unwrap().Load(key); ok {
+			t.Fatalf("LoadAndDelete(%q) left the entry behind", key)
+		}
+	}
+	if _, loaded := m.LoadAndDelete("absent"); loaded {
+		t.Fatal("LoadAndDelete of an absent key reported loaded")
+	}
+}
+
+func TestTapVisitsEveryEntry(t *testing.T) {
+	m := NewSyncMap(map[string]int{"a": 1, "b": 2, "c": 3})
+	calls := 0
+	if got := m.Tap(func(string, int) { calls++ }); got != &m {
+		t.Fatal("Tap did not return the map for chaining")
+	}
+	if calls != m.Len() {
+		t.Fatalf("Tap made %d calls, want Len() = %d", calls, m.Len())
+	}
+}
+
+func TestMergeReduceThreeMaps(t *testing.T) {
+	parts := []SyncMap[string, int]{
+		NewSyncMap(map[string]int{"a": 1, "b": 2}),
+		NewSyncMap(map[string]int{"b": 3, "c": 4}),
+		NewSyncMap(map[string]int{"a": 5, "b": 6}),
+	}
+	merged := MergeReduce(parts, func(a, b int) int { return a + b })
+	if want := map[string]int{"a": 6, "b": 11, "c": 4}; !maps.Equal(merged.ToMap(), want) {
+		t.Fatalf("MergeReduce = %v, want %v", merged.ToMap(), want)
+	}
+	if empty := MergeReduce[string](nil, func(a, b int) int { return a + b }); empty.Len() != 0 {
+		t.Fatalf("MergeReduce of no maps = %v, want empty", empty.ToMap())
+	}
+}
+
+func TestDistinctValues(t *testing.T) {
+	m := NewSyncMap(map[string]string{"a": "x", "b": "y", "c": "X", "d": "x"})
+	if got := DistinctValues(m); got != 3 {
+		t.Fatalf("DistinctValues = %d, want 3", got)
+	}
+	if got := m.DistinctValueCount(strings.EqualFold); got != 2 {
+		t.Fatalf("DistinctValueCount(EqualFold) = %d, want 2", got)
+	}
+	empty := NewSyncMap[string, string]()
+	if DistinctValues(empty) != 0 || empty.DistinctValueCount(strings.EqualFold) != 0 {
+		t.Fatal("an empty map has distinct values")
+	}
+}
+
+func TestRangeSampleFraction(t *testing.T) {
+	m := NewSyncMap[int, int]()
+	for i := 0; i < 10000; i++ {
+		m.Store(i, i)
+	}
+	count := func(fraction float64) int {
+		n := 0
+		m.RangeSample(fraction, rand.New(rand.NewSource(42)), func(int, int) bool {
+			n++
+			return true
+		})
+		return n
+	}
+	// With n=10000 and p=0.3 the standard deviation is about 46, so ±300 is over six sigma.
+	if got := count(0.3); got < 2700 || got > 3300 {
+		t.Fatalf("RangeSample(0.3) visited %d of 10000 entries, want about 3000", got)
+	}
+	if got := count(0); got != 0 {
+		t.Fatalf("RangeSample(0) visited %d entries", got)
+	}
+	if got := count(1); got != 10000 {
+		t.Fatalf("RangeSample(1) visited %d entries, want all 10000", got)
+	}
+}
+
+func TestRangeByHashIsReproducible(t *testing.T) {
+	m := NewSyncMap[string, int]()
+	for _, key := range []string{"q", "w", "e", "r", "t", "y"} {
+		m.Store(key, len(key))
+	}
+	hash := func(key string) uint64 { return uint64(key[0]) * 2654435761 % 97 }
+	order := func() []string {
+		var keys []string
+		m.RangeByHash(hash, func(key string, _ int) bool {
+			keys = append(keys, key)
+			return true
+		})
+		return keys
+	}
+	first := order()
+	if len(first) != 6 || !slices.IsSortedFunc(first, func(a, b string) int { return int(hash(a)) - int(hash(b)) }) {
+		t.Fatalf("RangeByHash order = %v, not ascending by hash", first)
+	}
+	if again := order(); !slices.Equal(first, again) {
+		t.Fatalf("RangeByHash gave %v then %v", first, again)
+	}
+}
+
+func TestEqualConsistentWithBackgroundMutator(t *testing.T) {
+	a := NewSyncMap(map[string]int{"x": 1, "y": 2})
+	b := NewSyncMap(map[string]int{"x": 1, "y": 2})
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		// Each batch leaves a unchanged but passes through an unequal state under the lock.
+		batch := []Op[string, int]{{Kind: OpSet, Key: "x", Value: 99}, {Kind: OpSet, Key: "x", Value: 1}}
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+				a.Apply(batch)
+			}
+		}
+	}()
+	for i := 0; i < 2000; i++ {
+		if !EqualConsistent(&a, &b) || !EqualConsistent(&b, &a) {
+			close(stop)
+			wg.Wait()
+			t.Fatalf("EqualConsistent observed a mid-batch state on iteration %d", i)
+		}
+	}
+	close(stop)
+	wg.Wait()
+	b.Store("y", 3)
+	if EqualConsistent(&a, &b) {
+		t.Fatal("EqualConsistent reported different maps equal")
+	}
+}
+
+func TestCanonicalBytesIdentical(t *testing.T) {
+	encode := func(key string, value int) []byte {
+		return []byte(key + "=" + string(rune('0'+value)))
+	}
+	a := NewSyncMap[string, int]()
+	b := NewSyncMap[string, int]()
+	for i, key := range []string{"x", "y", "z"} {
+		a.Store(key, i)
+	}
+	b.Store("z", 2)
+	b.Store("x", 0)
+	b.Store("y", 1)
+	if !bytes.Equal(a.CanonicalBytes(encode), b.CanonicalBytes(encode)) {
+		t.Fatal("identical contents inserted in different orders gave different bytes")
+	}
+	// Without length prefixes both maps would encode to "x=0y=1".
+	split := NewSyncMap(map[string]int{"x=0y": 1})
+	joined := NewSyncMap(map[string]int{"x": 0, "y": 1})
+	if bytes.Equal(split.CanonicalBytes(encode), joined.CanonicalBytes(encode)) {
+		t.Fatal("different contents gave identical bytes")
+	}
+}
+
+func TestDeleteIfValue(t *testing.T) {
+	m := NewSyncMap(map[string]int{"a": 1, "b": 2})
+	isOne := func(value int) bool { return value == 1 }
+	if value, ok := m.DeleteIfValue("a", isOne); !ok || value != 1 || m.Has("a") {
+		t.Fatalf("DeleteIfValue(pred true) = (%d, %v), Has = %v", value, ok, m.Has("a"))
+	}
+	if value, ok := m.DeleteIfValue("b", isOne); ok || value != 0 || !m.Has("b") {
+		t.Fatalf("DeleteIfValue(pred false) = (%d, %v), Has = %v", value, ok, m.Has("b"))
+	}
+	if _, ok := m.DeleteIfValue("absent", func(int) bool { return true }); ok {
+		t.Fatal("DeleteIfValue of an absent key reported a deletion")
+	}
+}
+
+func TestValuesUnique(t *testing.T) {
+	type user struct{ Email string }
+	m := NewSyncMap(map[int]user{1: {"a@x"}, 2: {"b@x"}})
+	email := func(u user) any { return strings.ToLower(u.Email) }
+	if !m.ValuesUnique(email) {
+		t.Fatal("ValuesUnique = false for distinct emails")
+	}
+	m.Store(3, user{"A@x"})
+	if m.ValuesUnique(email) {
+		t.Fatal("ValuesUnique = true with a duplicate projection")
+	}
+}
+
+func TestTransformValues(t *testing.T) {
+	m := NewSyncMap(map[string]int{"a": 100, "b": 50})
+	m.TransformValues(func(key string, value int) int {
+		if key == "a" {
+			return value * 9 / 10
+		}
+		return value
+	})
+	if want := map[string]int{"a": 90, "b": 50}; !maps.Equal(m.ToMap(), want) || m.Len() != 2 {
+		t.Fatalf("after TransformValues = %v (Len %d), want %v", m.ToMap(), m.Len(), want)
+	}
+}
+
+func TestGetMultiAlignsWithKeys(t *testing.T) {
+	m := NewSyncMap(map[string]int{"a": 1, "c": 3})
+	got := m.GetMulti([]string{"c", "missing", "a", "c"})
+	if !slices.Equal(got, []int{3, 0, 1, 3}) {
+		t.Fatalf("GetMulti = %v, want [3 0 1 3]", got)
+	}
+	if got := m.GetMulti(nil); len(got) != 0 {
+		t.Fatalf("GetMulti(nil) = %v", got)
+	}
+}
+
+func TestCompareAndSwapFuncOneWinner(t *testing.T) {
+	m := NewSyncMap(map[string][]string{"k": {"v0"}})
+	eq := func(a, b []string) bool { return slices.Equal(a, b) }
+	var wg sync.WaitGroup
+	wins := make(chan string, 2)
+	for _, value := range []string{"from-1", "from-2"} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if m.CompareAndSwapFunc("k", []string{"v0"}, []string{value}, eq) {
+				wins <- value
+			}
+		}()
+	}
+	wg.Wait()
+	close(wins)
+	var winners []string
+	for w := range wins {
+		winners = append(winners, w)
+	}
+	if len(winners) != 1 || !slices.Equal(m.Get("k"), []string{winners[0]}) {
+		t.Fatalf("winners = %v with k = %v, want exactly one winner holding the key", winners, m.Get("k"))
+	}
+	if m.CompareAndSwapFunc("absent", nil, []string{"x"}, eq) {
+		t.Fatal("CompareAndSwapFunc swapped an absent key")
+	}
+}
+
+func TestAbsorbConsistentWithBackgroundWriters(t *testing.T) {
+	m := NewSyncMap[int, int]()
+	other := NewSyncMap[int, int]()
+	var wg sync.WaitGroup
+	// Writers on other use keys 0..1999 and writers on m use keys 2000..3999.
+	for g := 0; g < 4; g++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for i := g; i < 2000; i += 4 {
+				other.Store(i, i)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for i := 2000 + g; i < 4000; i += 4 {
+				m.Store(i, i)
+			}
+		}()
+	}
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	for absorbing := true; absorbing; {
+		select {
+		case <-done:
+			absorbing = false
+		default:
+		}
+		m.AbsorbConsistent(&other, true)
+	}
+	if other.Len() != 0 {
+		t.Fatalf("other holds %d entries after the final absorb, want 0", other.Len())
+	}
+	if m.Len() != 4000 {
+		t.Fatalf("m holds %d entries, want all 4000 writes to both maps", m.Len())
+	}
+	for i := 0; i < 4000; i++ {
+		if value, ok := m.Load(i); !ok || value != i {
+			t.Fatalf("entry %d lost: (%d, %v)", i, value, ok)
+		}
+	}
+}
+
+func TestFilterMap(t *testing.T) {
+	m := NewSyncMap(map[string]int{"a": 1, "b": 2, "c": 3, "d": 4})
+	evens := FilterMap(m, func(key string, value int) (string, string, bool) {
+		return strings.ToUpper(key), strings.Repeat("*", value), value%2 == 0
+	})
+	if want := map[string]string{"B": "**", "D": "****"}; !maps.Equal(evens.ToMap(), want) {
+		t.Fatalf("FilterMap = %v, want %v", evens.ToMap(), want)
+	}
+	if m.Len() != 4 {
+		t.Fatal("FilterMap changed its source")
+	}
+}
+
+func TestSwapContentsReadersSeeWholeContents(t *testing.T) {
+	first := map[string]int{"a": 1, "b": 2}
+	second := map[string]int{"x": 10, "y": 20, "z": 30}
+	a := NewSyncMap(first)
+	b := NewSyncMap(second)
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+				SwapContents(&a, &b)
+			}
+		}
+	}()
+	for i := 0; i < 2000; i++ {
+		if got := a.ToMap(); !maps.Equal(got, first) && !maps.Equal(got, second) {
+			close(stop)
+			wg.Wait()
+			t.Fatalf("reader saw a mix of contents: %v", got)
+		}
+	}
+	close(stop)
+	wg.Wait()
+}
@@ -0,0 +1,80 @@
+package asyncmap
+
+// OpKind selects what an Op does when passed to Apply.
+type OpKind int
+
+const (
+	// OpSet stores Value under Key.
+	OpSet OpKind = iota
+	// OpDelete deletes Key.
+	OpDelete
+	// OpGet loads Key.
+	OpGet
+	// OpCAS stores Value under Key only if the current value equals Old.
+	OpCAS
+)
+
+// Op is a single operation for Apply.
+type Op[K comparable, V any] struct {
+	Kind  OpKind
+	Key   K
+	Value V
+	// Old is the expected current value for OpCAS.
+	Old V
+	// Equal compares values for OpCAS. If nil, values are compared with ==,
+	// which panics if V's dynamic type is not comparable.
+	Equal func(a, b V) bool
+}
+
+// OpResult is the outcome of one Op applied by Apply.
+//   - OpSet: OK is true and Value is the stored value.
+//   - OpDelete: OK reports whether the key existed and Value is the deleted value.
+//   - OpGet: OK and Value are as returned by Load.
+//   - OpCAS: OK reports whether the swap happened and Value is the key's value afterwards.
+type OpResult[V any] struct {
+	Value V
+	OK    bool
+}
+
+// Apply executes ops in order as a single unit under the local lock and returns one result per op.
+// No other composite operation interleaves with the batch, which makes Apply suitable for replaying
+// a write-ahead log into the map. Lock-free readers (Load, Get, ...) may still observe the batch
+// part-way through.
+func (m *SyncMap[K, V]) Apply(ops []Op[K, V]) []OpResult[V] {
+	m.lazyInit()
+	for _, op := range ops {
+		if op.Kind != OpGet {
+			m.mustBeWritable()
+			break
+		}
+	}
+	m.core.localLock.Lock()
+	defer m.core.localLock.Unlock()
+	results := make([]OpResult[V], len(ops))
+	for i, op := range ops {
+		switch op.Kind {
+		case OpSet:
+			m.Store(op.Key, op.Value)
+			results[i] = OpResult[V]{Value: op.Value, OK: true}
+		case OpDelete:
+			value, ok := m.LoadAndDelete(op.Key)
+			results[i] = OpResult[V]{Value: value, OK: ok}
+		case OpGet:
+			value, ok := m.Load(op.Key)
+			results[i] = OpResult[V]{Value: value, OK: ok}
+		case OpCAS:
+			current, ok := m.LoadPresent(op.Key)
+			equal := op.Equal
+			if equal == nil {
+				equal = func(a, b V) bool { return any(a) == any(b) }
+			}
+			if ok && equal(current, op.Old) {
+				m.Store(op.Key, op.Value)
+				results[i] = OpResult[V]{Value: op.Value, OK: true}
+			} else {
+				results[i] = OpResult[V]{Value: current, OK: false}
+			}
+		}
+	}
+	return results
+}
@@ -0,0 +1,83 @@
+package asyncmap
+
+import (
+	"maps"
+	"slices"
+	"sync"
+	"testing"
+)
+
+func TestApplyMixedOps(t *testing.T) {
+	m := NewSyncMap(map[string]int{"a": 1, "b": 2})
+	results := m.Apply([]Op[string, int]{
+		{Kind: OpSet, Key: "c", Value: 3},
+		{Kind: OpGet, Key: "c"},
+		{Kind: OpDelete, Key: "a"},
+		{Kind: OpDelete, Key: "a"},
+		{Kind: OpCAS, Key: "b", Old: 2, Value: 20},
+		{Kind: OpCAS, Key: "b", Old: 2, Value: 200},
+		{Kind: OpGet, Key: "a"},
+	})
+	want := []OpResult[int]{
+		{Value: 3, OK: true},
+		{Value: 3, OK: true},
+		{Value: 1, OK: true},
+		{Value: 0, OK: false},
+		{Value: 20, OK: true},
+		{Value: 20, OK: false},
+		{Value: 0, OK: false},
+	}
+	if !slices.Equal(results, want) {
+		t.Fatalf("Apply = %v, want %v", results, want)
+	}
+	if want := map[string]int{"b": 20, "c": 3}; !maps.Equal(m.ToMap(), want) {
+		t.Fatalf("after Apply = %v, want %v", m.ToMap(), want)
+	}
+}
+
+func TestApplyBatchNeverSeenHalfApplied(t *testing.T) {
+	m := NewSyncMap(map[string]int{"a": 1, "b": 0})
+	toB := []Op[string, int]{{Kind: OpSet, Key: "a", Value: 0}, {Kind: OpSet, Key: "b", Value: 1}}
+	toA := []Op[string, int]{{Kind: OpSet, Key: "b", Value: 0}, {Kind: OpSet, Key: "a", Value: 1}}
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; ; i++ {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			if i%2 == 0 {
+				m.Apply(toB)
+			} else {
+				m.Apply(toA)
+			}
+		}
+	}()
+	defer func() {
+		close(stop)
+		wg.Wait()
+	}()
+	// The two states a reader could only see part-way through a batch.
+	neither := NewSyncMap(map[string]int{"a": 0, "b": 0})
+	both := NewSyncMap(map[string]int{"a": 1, "b": 1})
+	for i := 0; i < 2000; i++ {
+		if snapshot := m.ToMap(); snapshot["a"]+snapshot["b"] != 1 {
+			t.Fatalf("ToMap saw a half-applied batch: %v", snapshot)
+		}
+		sum := 0
+		m.Range(func(_ string, value int) bool {
+			sum += value
+			return true
+		})
+		if sum != 1 {
+			t.Fatalf("Range saw a half-applied batch: sum %d", sum)
+		}
+		if EqualConsistent(&m, &neither) || EqualConsistent(&m, &both) {
+			t.Fatal("EqualConsistent saw a half-applied batch")
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package asyncmap
+
+// Builder constructs a SyncMap fluently, e.g. when some entries are conditional:
+//
+//	m := asyncmap.NewBuilder[string, int]().
+//		Set("a", 1).
+//		SetIf(debug, "trace", 1).
+//		Build()
+//
+// Entries accumulate in a plain map and the SyncMap is built once by Build.
+// A Builder is not safe for concurrent use.
+type Builder[K comparable, V any] struct {
+	entries map[K]V
+}
+
+// NewBuilder creates an empty Builder.
+func NewBuilder[K comparable, V any]() *Builder[K, V] {
+	return &Builder[K, V]{entries: make(map[K]V)}
+}
+
+// Set adds an entry, replacing any earlier entry for key.
+func (b *Builder[K, V]) Set(key K, value V) *Builder[K, V] {
+	b.entries[key] = value
+	return b
+}
+
+// SetMap adds every entry of entries, replacing earlier entries for the same keys.
+func (b *Builder[K, V]) SetMap(entries map[K]V) *Builder[K, V] {
+	for key, value := range entries {
+		b.entries[key] = value
+	}
+	return b
+}
+
+// SetIf adds an entry only when cond is true.
+func (b *Builder[K, V]) SetIf(cond bool, key K, value V) *Builder[K, V] {
+	if cond {
+		b.entries[key] = value
+	}
+	return b
+}
+
+// Build returns a new SyncMap holding the accumulated entries.
+// The Builder can keep being used; later changes don't affect maps already built.
+func (b *Builder[K, V]) Build() SyncMap[K, V] {
+	return NewSyncMap(b.entries)
+}
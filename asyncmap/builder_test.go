@@ -0,0 +1,22 @@
+package asyncmap
+
+import (
+	"maps"
+	"testing"
+)
+
+func TestBuilderConditionalEntries(t *testing.T) {
+	b := NewBuilder[string, int]().
+		Set("a", 1).
+		SetIf(true, "on", 2).
+		SetIf(false, "off", 3).
+		SetMap(map[string]int{"a": 10, "b": 20})
+	m := b.Build()
+	if want := map[string]int{"a": 10, "on": 2, "b": 20}; !maps.Equal(m.ToMap(), want) {
+		t.Fatalf("Build = %v, want %v", m.ToMap(), want)
+	}
+	b.Set("later", 4)
+	if m.Has("later") {
+		t.Fatal("a Set after Build changed the built map")
+	}
+}
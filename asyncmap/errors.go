@@ -0,0 +1,26 @@
+package asyncmap
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrFull is returned by TryStore when storing a new key would exceed the map's maximum size.
+var ErrFull = errors.New("asyncmap: map is full")
+
+// ErrKeyNotFound is the sentinel matched by KeyNotFoundError under errors.Is.
+var ErrKeyNotFound = errors.New("asyncmap: key not found")
+
+// KeyNotFoundError is returned by GetOrErr on a miss and carries the missing key.
+type KeyNotFoundError struct {
+	Key any
+}
+
+func (e *KeyNotFoundError) Error() string {
+	return fmt.Sprintf("asyncmap: key not found: %v", e.Key)
+}
+
+// Is reports whether target is ErrKeyNotFound, so errors.Is(err, ErrKeyNotFound) matches.
+func (e *KeyNotFoundError) Is(target error) bool {
+	return target == ErrKeyNotFound
+}
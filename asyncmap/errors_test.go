@@ -0,0 +1,26 @@
+package asyncmap
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestGetOrErr(t *testing.T) {
+	m := NewSyncMap(map[string]int{"a": 1})
+	if value, err := m.GetOrErr("a"); value != 1 || err != nil {
+		t.Fatalf("GetOrErr(a) = (%d, %v)", value, err)
+	}
+	_, err := m.GetOrErr("missing")
+	wrapped := fmt.Errorf("loading config: %w", err)
+	if !errors.Is(wrapped, ErrKeyNotFound) {
+		t.Fatalf("errors.Is(%v, ErrKeyNotFound) = false", wrapped)
+	}
+	var notFound *KeyNotFoundError
+	if !errors.As(wrapped, &notFound) || notFound.Key != "missing" {
+		t.Fatalf("KeyNotFoundError.Key = %v, want missing", notFound)
+	}
+	if errors.Is(err, ErrFull) {
+		t.Fatal("KeyNotFoundError matched an unrelated sentinel")
+	}
+}
@@ -0,0 +1,18 @@
+package asyncmap_test
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/Patrick-ring-motive/async-map/asyncmap"
+)
+
+func ExampleCollect() {
+	m := asyncmap.NewSyncMap(map[string]int{"b": 2, "c": 3, "a": 1})
+	keys := asyncmap.Collect(m, []string(nil), func(keys []string, k string, _ int) []string {
+		return append(keys, k)
+	})
+	slices.Sort(keys)
+	fmt.Println(keys)
+	// Output: [a b c]
+}
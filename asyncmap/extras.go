@@ -0,0 +1,46 @@
+package asyncmap
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// extras holds the state of the map's rarely used features. It is allocated the first time
+// one of them is used, so a plain map costs no more than its core and table.
+type extras[K comparable, V any] struct {
+	frozen    atomic.Bool
+	threshold atomic.Pointer[sizeThreshold]
+	// versions holds the current version generation once the versioned API is first used.
+	versions atomic.Pointer[versionTable]
+	// tees holds the replicas registered with Tee.
+	tees atomic.Pointer[[]*SyncMap[K, V]]
+	// mismatches counts failed type assertions on stored keys and values.
+	mismatches atomic.Uint64
+	// flights holds the loads in progress in GetOrLoad, keyed like the map.
+	flights sync.Map
+	// panics holds the policy set with SetPanicPolicy; nil means PanicRecover.
+	panics atomic.Pointer[panicPolicy[K, V]]
+	// jsonKeys holds the key codec set with SetJSONKeyCodec.
+	jsonKeys atomic.Pointer[jsonKeyCodec[K]]
+	// emptied is closed when the map becomes empty while WaitEmpty callers are waiting.
+	emptied atomic.Pointer[chan struct{}]
+	// access holds per-key access counters while TrackAccess is enabled.
+	access atomic.Pointer[sync.Map]
+	// maxSize is the limit enforced by TryStore; 0 means unlimited.
+	maxSize atomic.Int64
+}
+
+// loadExtras returns the map's extras, or nil if no feature needing them has been used.
+// Hot paths check for nil to skip the features in one step.
+func (m *SyncMap[K, V]) loadExtras() *extras[K, V] {
+	return m.core.extras.Load()
+}
+
+// initExtras returns the map's extras, allocating them on first use.
+func (m *SyncMap[K, V]) initExtras() *extras[K, V] {
+	if e := m.core.extras.Load(); e != nil {
+		return e
+	}
+	m.core.extras.CompareAndSwap(nil, &extras[K, V]{})
+	return m.core.extras.Load()
+}
@@ -0,0 +1,29 @@
+package asyncmap
+
+// tombstone is stored in a forked map to record that a key was deleted in the child.
+// It hides the parent's entry for that key from every read on the child.
+type tombstone struct{}
+
+// isTombstone reports whether a raw value read from the underlying sync.Map is a tombstone.
+func isTombstone(value any) bool {
+	_, ok := value.(tombstone)
+	return ok
+}
+
+// Fork returns a copy-on-write child of the map.
+//
+// The child reads through to the parent: a key the child has never written or deleted
+// resolves to the parent's current entry, including writes made to the parent after the fork.
+// Once the child stores or deletes a key, the child owns that key and shadows the parent's
+// entry for good. Writes to the child never touch the parent, so handing out forks of a large
+// baseline map avoids copying it per consumer.
+//
+// Composite operations on the child (Range, Clear, Len, ...) see the merged view, but only
+// lock the child; they are not atomic with respect to writes made directly to the parent.
+func (m *SyncMap[K, V]) Fork() *SyncMap[K, V] {
+	m.lazyInit()
+	parent := *m
+	child := NewSyncMap[K, V]()
+	child.parent = &parent
+	return &child
+}
@@ -0,0 +1,41 @@
+package asyncmap
+
+import (
+	"maps"
+	"testing"
+)
+
+func TestForkIsolatesParent(t *testing.T) {
+	parent := NewSyncMap(map[string]int{"a": 1, "b": 2})
+	child := parent.Fork()
+	child.Store("a", 10)
+	child.Store("c", 3)
+	child.Delete("b")
+	if want := map[string]int{"a": 1, "b": 2}; !maps.Equal(parent.ToMap(), want) {
+		t.Fatalf("parent = %v, want %v", parent.ToMap(), want)
+	}
+	if want := map[string]int{"a": 10, "c": 3}; !maps.Equal(child.ToMap(), want) {
+		t.Fatalf("child = %v, want %v", child.ToMap(), want)
+	}
+	if child.Len() != 2 {
+		t.Fatalf("child Len = %d, want 2", child.Len())
+	}
+}
+
+func TestForkReadsThroughToParent(t *testing.T) {
+	parent := NewSyncMap(map[string]int{"a": 1, "b": 2})
+	child := parent.Fork()
+	child.Delete("b")
+	parent.Store("a", 5)
+	parent.Store("b", 6)
+	parent.Store("d", 4)
+	if got := child.Get("a"); got != 5 {
+		t.Fatalf("child Get(a) = %d, want the parent's later write 5", got)
+	}
+	if got := child.Get("d"); got != 4 {
+		t.Fatalf("child Get(d) = %d, want 4", got)
+	}
+	if child.Has("b") {
+		t.Fatal("child's delete of b was undone by a parent write")
+	}
+}
@@ -0,0 +1,80 @@
+package asyncmap
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// TrackAccess turns per-key access counting on or off. While on, every lookup the caller makes
+// (Load, Get, GetOrDefault and the lookups of GetAll, GetMulti, GetOrErr and Apply) increments a
+// counter for the key, hit or miss, so HotKeys can report which keys dominate traffic.
+// Reads other methods make internally, such as FetchAdd's, are not counted. It is off by default to avoid the overhead.
+// Turning it on starts from zero; turning it off discards the counts.
+func (m *SyncMap[K, V]) TrackAccess(enable bool) {
+	m.lazyInit()
+	if !enable {
+		if e := m.loadExtras(); e != nil {
+			e.access.Store(nil)
+		}
+		return
+	}
+	m.initExtras().access.CompareAndSwap(nil, &sync.Map{})
+}
+
+// ResetAccessCounts sets every access counter back to zero, if tracking is on.
+func (m *SyncMap[K, V]) ResetAccessCounts() {
+	m.lazyInit()
+	if e := m.loadExtras(); e != nil && e.access.Load() != nil {
+		e.access.Store(&sync.Map{})
+	}
+}
+
+// HotKeys returns the n most-accessed keys since tracking was enabled or last reset,
+// most-accessed first, with their access counts. It returns nil if tracking is off.
+func (m *SyncMap[K, V]) HotKeys(n int) []Entry[K, int] {
+	m.lazyInit()
+	e := m.loadExtras()
+	if e == nil || e.access.Load() == nil {
+		return nil
+	}
+	access := e.access.Load()
+	counts := NewSyncMap[K, int]()
+	access.Range(func(key, counter any) bool {
+		counts.Store(key.(K), int(counter.(*atomic.Int64).Load()))
+		return true
+	})
+	return TopN(counts, n, func(a, b int) bool { return a < b })
+}
+
+// accessTotals returns the total number of lookups counted and the number of distinct
+// keys looked up, or zeros if tracking is off.
+func (m *SyncMap[K, V]) accessTotals() (lookups uint64, keys int) {
+	m.lazyInit()
+	e := m.loadExtras()
+	if e == nil || e.access.Load() == nil {
+		return 0, 0
+	}
+	e.access.Load().Range(func(_, counter any) bool {
+		lookups += uint64(counter.(*atomic.Int64).Load())
+		keys++
+		return true
+	})
+	return lookups, keys
+}
+
+// recordAccess counts a lookup of key if access tracking is on.
+func (m *SyncMap[K, V]) recordAccess(key K) {
+	e := m.loadExtras()
+	if e == nil {
+		return
+	}
+	access := e.access.Load()
+	if access == nil {
+		return
+	}
+	counter, ok := access.Load(key)
+	if !ok {
+		counter, _ = access.LoadOrStore(key, &atomic.Int64{})
+	}
+	counter.(*atomic.Int64).Add(1)
+}
@@ -0,0 +1,49 @@
+package asyncmap
+
+import "testing"
+
+func TestHotKeysRanking(t *testing.T) {
+	m := NewSyncMap(map[string]int{"a": 1, "b": 2, "c": 3})
+	m.TrackAccess(true)
+	for i := 0; i < 5; i++ {
+		m.Load("a")
+	}
+	for i := 0; i < 3; i++ {
+		m.Get("b")
+	}
+	m.GetOrDefault("missing", 0)
+
+	hot := m.HotKeys(2)
+	if len(hot) != 2 || hot[0] != (Entry[string, int]{"a", 5}) || hot[1] != (Entry[string, int]{"b", 3}) {
+		t.Fatalf("HotKeys(2) = %v", hot)
+	}
+
+	m.ResetAccessCounts()
+	if hot := m.HotKeys(3); len(hot) != 0 {
+		t.Fatalf("HotKeys after reset = %v", hot)
+	}
+	m.TrackAccess(false)
+	if m.HotKeys(3) != nil {
+		t.Fatal("HotKeys returned counts with tracking off")
+	}
+}
+
+func TestHotKeysIgnoresInternalReads(t *testing.T) {
+	counters := NewSyncMap[string, int]()
+	counters.TrackAccess(true)
+	FetchAdd(&counters, "hits", 1)
+	IncrCapped(&counters, "hits", 1, 10)
+	counters.Move("hits", "moved")
+	counters.DeleteIfValue("moved", func(int) bool { return false })
+	if hot := counters.HotKeys(5); len(hot) != 0 {
+		t.Fatalf("internal reads were counted: %v", hot)
+	}
+
+	lists := NewSyncMap[string, []int]()
+	lists.TrackAccess(true)
+	AppendTo(&lists, "k", 1)
+	RemoveFrom(&lists, "k", 1)
+	if hot := lists.HotKeys(5); len(hot) != 0 {
+		t.Fatalf("internal reads were counted: %v", hot)
+	}
+}
@@ -0,0 +1,124 @@
+package asyncmap
+
+import (
+	"encoding/base64"
+	"fmt"
+	"iter"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// KeyBatches returns an iterator over the map's keys in slices of up to size keys, for
+// processing a large map in chunks (for example bulk database writes). Keys are gathered
+// while walking the map, without the local lock and without snapshotting the whole map
+// first, so entries written or deleted during iteration may or may not be seen.
+// Each batch is a fresh slice that the caller may keep. A size below 1 is treated as 1.
+func (m *SyncMap[K, V]) KeyBatches(size int) iter.Seq[[]K] {
+	size = max(size, 1)
+	return func(yield func([]K) bool) {
+		m.lazyInit()
+		batch := make([]K, 0, size)
+		stopped := false
+		m.rangeRaw(func(key, value any) bool {
+			typedKey, typedKeyOk := m.typedKey(key)
+			_, typedValueOk := m.typedValue(value)
+			if !typedKeyOk || !typedValueOk {
+				return true
+			}
+			batch = append(batch, typedKey)
+			if len(batch) < size {
+				return true
+			}
+			if !yield(batch) {
+				stopped = true
+				return false
+			}
+			batch = make([]K, 0, size)
+			return true
+		})
+		if !stopped && len(batch) > 0 {
+			yield(batch)
+		}
+	}
+}
+
+// Entry is a single key/value pair from a SyncMap.
+type Entry[K comparable, V any] struct {
+	Key   K
+	Value V
+}
+
+// Entries returns an iterator over the map's entries as Entry values, for use with
+// range-over-func. Like Range, it holds the local lock for the duration of the loop,
+// so the loop body must not call methods that take it (Clear, Range, ...).
+// Breaking out of the loop stops the iteration. Unlike Range, a panic in the loop body
+// is not recovered.
+func (m *SyncMap[K, V]) Entries() iter.Seq[Entry[K, V]] {
+	return func(yield func(Entry[K, V]) bool) {
+		m.lazyInit()
+		m.core.localLock.Lock()
+		defer m.core.localLock.Unlock()
+		m.rangeRaw(func(key, value any) bool {
+			typedKey, typedKeyOk := m.typedKey(key)
+			typedValue, typedValueOk := m.typedValue(value)
+			if !typedKeyOk || !typedValueOk {
+				return true
+			}
+			return yield(Entry[K, V]{Key: typedKey, Value: typedValue})
+		})
+	}
+}
+
+// Page returns up to limit entries following cursor, and the cursor for the next page, for
+// paginating the map over an API. Pass "" for the first page; nextCursor is "" once the last
+// page has been returned. Keys are ordered by their type and Go-syntax representation
+// (fmt's "%T %#v"), which is stable for a given key. Distinct keys sharing a representation,
+// such as values of identically named types from different packages, are told apart by their
+// position among themselves, which is only stable while the map is unchanged. An unparseable
+// cursor yields no entries. Each call snapshots the keys, so entries written or deleted
+// between calls may be skipped or repeated across pages. A limit below 1 is treated as 1.
+func (m *SyncMap[K, V]) Page(cursor string, limit int) (entries []Entry[K, V], nextCursor string) {
+	limit = max(limit, 1)
+	var after string
+	skip := 0
+	if cursor != "" {
+		encoded, index, found := strings.Cut(cursor, ":")
+		decoded, err := base64.RawURLEncoding.DecodeString(encoded)
+		n, convErr := strconv.Atoi(index)
+		if !found || err != nil || convErr != nil {
+			return nil, ""
+		}
+		after, skip = string(decoded), n
+	}
+	type ranked struct {
+		key  K
+		repr string
+		// pos is the key's position among keys with the same repr.
+		pos int
+	}
+	var keys []ranked
+	for _, key := range m.Keys() {
+		keys = append(keys, ranked{key: key, repr: fmt.Sprintf("%T %#v", key, key)})
+	}
+	slices.SortStableFunc(keys, func(a, b ranked) int { return strings.Compare(a.repr, b.repr) })
+	for i := range keys {
+		if i > 0 && keys[i].repr == keys[i-1].repr {
+			keys[i].pos = keys[i-1].pos + 1
+		}
+	}
+	var last ranked
+	for _, k := range keys {
+		if cursor != "" && (k.repr < after || k.repr == after && k.pos < skip) {
+			continue
+		}
+		if len(entries) == limit {
+			return entries, base64.RawURLEncoding.EncodeToString([]byte(last.repr)) + ":" + strconv.Itoa(last.pos+1)
+		}
+		if value, ok := m.LoadPresent(k.key); ok {
+			entries = append(entries, Entry[K, V]{Key: k.key, Value: value})
+			last = k
+		}
+	}
+	return entries, ""
+}
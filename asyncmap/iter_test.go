@@ -0,0 +1,145 @@
+package asyncmap
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestPageVisitsEveryEntryOnce(t *testing.T) {
+	m := NewSyncMap[int, int]()
+	for i := 0; i < 25; i++ {
+		m.Store(i, i*10)
+	}
+	seen := make(map[int]bool)
+	cursor, pages := "", 0
+	for {
+		entries, next := m.Page(cursor, 10)
+		pages++
+		for _, entry := range entries {
+			if seen[entry.Key] {
+				t.Fatalf("key %d returned twice", entry.Key)
+			}
+			if entry.Value != entry.Key*10 {
+				t.Fatalf("entry %d has value %d", entry.Key, entry.Value)
+			}
+			seen[entry.Key] = true
+		}
+		if next == "" {
+			break
+		}
+		cursor = next
+	}
+	if len(seen) != 25 || pages != 3 {
+		t.Fatalf("visited %d entries in %d pages, want 25 in 3", len(seen), pages)
+	}
+}
+
+func TestPageKeysWithSameRepresentation(t *testing.T) {
+	m := NewSyncMap[any, string]()
+	m.Store(int(1), "int")
+	m.Store(int64(1), "int64")
+	m.Store("1", "string")
+	seen := make(map[any]bool)
+	cursor := ""
+	for {
+		entries, next := m.Page(cursor, 1)
+		for _, entry := range entries {
+			seen[entry.Key] = true
+		}
+		if next == "" {
+			break
+		}
+		cursor = next
+	}
+	if len(seen) != 3 {
+		t.Fatalf("visited %d of 3 entries: %v", len(seen), seen)
+	}
+}
+
+func TestPageRejectsBadCursor(t *testing.T) {
+	m := NewSyncMap(map[string]int{"a": 1})
+	if entries, next := m.Page("!!", 5); entries != nil || next != "" {
+		t.Fatalf("Page(bad cursor) = %v, %q", entries, next)
+	}
+}
+
+// tiedKey formats every value identically under %#v, to force Page's tie-breaking.
+type tiedKey struct{ id int }
+
+func (tiedKey) GoString() string { return "tied" }
+
+func TestPageBreaksRepresentationTies(t *testing.T) {
+	m := NewSyncMap[tiedKey, int]()
+	for i := 0; i < 5; i++ {
+		m.Store(tiedKey{i}, i)
+	}
+	seen := make(map[tiedKey]bool)
+	cursor := ""
+	for {
+		entries, next := m.Page(cursor, 2)
+		for _, entry := range entries {
+			seen[entry.Key] = true
+		}
+		if next == "" {
+			break
+		}
+		cursor = next
+	}
+	if len(seen) != 5 {
+		t.Fatalf("visited %d of 5 tied entries", len(seen))
+	}
+}
+
+func TestKeyBatches(t *testing.T) {
+	m := NewSyncMap[int, int]()
+	for i := 0; i < 10; i++ {
+		m.Store(i, i)
+	}
+	var sizes, keys []int
+	for batch := range m.KeyBatches(4) {
+		sizes = append(sizes, len(batch))
+		keys = append(keys, batch...)
+	}
+	if !slices.Equal(sizes, []int{4, 4, 2}) {
+		t.Fatalf("batch sizes = %v, want [4 4 2]", sizes)
+	}
+	slices.Sort(keys)
+	if !slices.Equal(keys, []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}) {
+		t.Fatalf("keys covered = %v", keys)
+	}
+}
+
+func TestKeyBatchesBreak(t *testing.T) {
+	m := NewSyncMap[int, int]()
+	for i := 0; i < 10; i++ {
+		m.Store(i, i)
+	}
+	batches := 0
+	for range m.KeyBatches(3) {
+		batches++
+		break
+	}
+	if batches != 1 {
+		t.Fatalf("loop body ran %d times after break, want 1", batches)
+	}
+}
+
+func TestEntriesCollectAndBreak(t *testing.T) {
+	m := NewSyncMap(map[string]int{"a": 1, "b": 2, "c": 3})
+	got := make(map[string]int)
+	for entry := range m.Entries() {
+		got[entry.Key] = entry.Value
+	}
+	if len(got) != 3 || got["a"] != 1 || got["b"] != 2 || got["c"] != 3 {
+		t.Fatalf("Entries collected %v", got)
+	}
+	visited := 0
+	for range m.Entries() {
+		visited++
+		break
+	}
+	if visited != 1 {
+		t.Fatalf("loop body ran %d times after break, want 1", visited)
+	}
+	m.Clear() // the lock taken by Entries must have been released by the break
+}
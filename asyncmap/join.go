@@ -0,0 +1,33 @@
+package asyncmap
+
+// Pair holds two related values, such as the values joined from two maps under one key.
+type Pair[A, B any] struct {
+	First  A
+	Second B
+}
+
+// Zip joins a and b on their keys (an inner join): the result holds every key present in
+// both maps, paired with the two source values. Keys present in only one map are omitted.
+// The result is independent of a and b.
+func Zip[K comparable, A, B any](a SyncMap[K, A], b SyncMap[K, B]) SyncMap[K, Pair[A, B]] {
+	out := NewSyncMap[K, Pair[A, B]]()
+	a.Range(func(key K, first A) bool {
+		if second, ok := b.Load(key); ok {
+			out.Store(key, Pair[A, B]{First: first, Second: second})
+		}
+		return true
+	})
+	return out
+}
+
+// Combine joins b onto a (a left outer join): the result holds every key of a, paired with
+// b's value for that key, or with defB when b has no value for it. Keys only in b are omitted.
+// The result is independent of a and b.
+func Combine[K comparable, A, B any](a SyncMap[K, A], b SyncMap[K, B], defB B) SyncMap[K, Pair[A, B]] {
+	out := NewSyncMap[K, Pair[A, B]]()
+	a.Range(func(key K, first A) bool {
+		out.Store(key, Pair[A, B]{First: first, Second: b.GetOrDefault(key, defB)})
+		return true
+	})
+	return out
+}
@@ -0,0 +1,30 @@
+package asyncmap
+
+import (
+	"maps"
+	"testing"
+)
+
+func TestZip(t *testing.T) {
+	a := NewSyncMap(map[string]int{"x": 1, "y": 2, "only-a": 3})
+	b := NewSyncMap(map[string]string{"x": "one", "y": "two", "only-b": "four"})
+	want := map[string]Pair[int, string]{"x": {1, "one"}, "y": {2, "two"}}
+	zipped := Zip(a, b)
+	if got := zipped.ToMap(); !maps.Equal(got, want) {
+		t.Fatalf("Zip = %v, want %v", got, want)
+	}
+	disjoint := NewSyncMap(map[string]string{"z": "zed"})
+	if got := Zip(a, disjoint); got.Len() != 0 {
+		t.Fatalf("Zip of disjoint maps = %v, want empty", got.ToMap())
+	}
+}
+
+func TestCombineFillsDefault(t *testing.T) {
+	a := NewSyncMap(map[string]int{"x": 1, "y": 2})
+	b := NewSyncMap(map[string]string{"x": "one", "only-b": "four"})
+	want := map[string]Pair[int, string]{"x": {1, "one"}, "y": {2, "n/a"}}
+	combined := Combine(a, b, "n/a")
+	if got := combined.ToMap(); !maps.Equal(got, want) {
+		t.Fatalf("Combine = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,262 @@
+package asyncmap
+
+import (
+	"bufio"
+	"bytes"
+	"cmp"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"slices"
+	"strconv"
+)
+
+// MarshalJSON encodes a snapshot of the map as a JSON object.
+// Keys follow encoding/json's rules for map keys: K must be a string, an integer type,
+// or implement encoding.TextMarshaler. Entries are always written sorted by their JSON key,
+// so marshaling the same contents yields byte-for-byte identical output.
+// Like MarshalYAML it has a value receiver, so json.Marshal also finds it when handed a
+// struct by value, whose SyncMap fields aren't addressable.
+func (m SyncMap[K, V]) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := m.EncodeJSON(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalJSON decodes a JSON object into the map, storing every decoded entry.
+// Existing entries whose keys are not present in the document are left untouched.
+// Keys are decoded with the codec set by SetJSONKeyCodec, if any.
+func (m *SyncMap[K, V]) UnmarshalJSON(data []byte) error {
+	m.lazyInit()
+	codec := m.keyCodec()
+	if codec == nil {
+		decoded := make(map[K]V)
+		if err := json.Unmarshal(data, &decoded); err != nil {
+			return err
+		}
+		for key, value := range decoded {
+			m.Store(key, value)
+		}
+		return nil
+	}
+	decoded := make(map[string]V)
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+	typed := make(map[K]V, len(decoded))
+	for name, value := range decoded {
+		key, err := codec.stringToKey(name)
+		if err != nil {
+			return fmt.Errorf("asyncmap: JSON key %q: %w", name, err)
+		}
+		typed[key] = value
+	}
+	for key, value := range typed {
+		m.Store(key, value)
+	}
+	return nil
+}
+
+// MergeJSON applies a JSON object to a string-keyed map as a patch: keys in the document are
+// stored, overwriting existing values, and keys absent from it are left alone. This suits
+// config hot-reload with incremental patches. The whole document is decoded before anything
+// is written, so malformed JSON returns an error and leaves the map unchanged; the decoded
+// entries are then applied in one BatchStore.
+func MergeJSON[V any](m *SyncMap[string, V], data []byte) error {
+	var patch map[string]V
+	if err := json.Unmarshal(data, &patch); err != nil {
+		return err
+	}
+	m.BatchStore(patch)
+	return nil
+}
+
+// jsonKeyCodec converts keys to and from JSON object keys; see SetJSONKeyCodec.
+type jsonKeyCodec[K comparable] struct {
+	keyToString func(K) string
+	stringToKey func(string) (K, error)
+}
+
+// SetJSONKeyCodec makes MarshalJSON, UnmarshalJSON and the other JSON object encoders of this
+// map convert keys with keyToString and stringToKey instead of encoding/json's default rules.
+// This lets enum-like keys (e.g. `type State int` with a String method) be written by name.
+// Passing nil for either function restores the default behavior.
+func (m *SyncMap[K, V]) SetJSONKeyCodec(keyToString func(K) string, stringToKey func(string) (K, error)) {
+	m.lazyInit()
+	if keyToString == nil || stringToKey == nil {
+		m.initExtras().jsonKeys.Store(nil)
+		return
+	}
+	m.initExtras().jsonKeys.Store(&jsonKeyCodec[K]{keyToString: keyToString, stringToKey: stringToKey})
+}
+
+// keyCodec returns the key codec set with SetJSONKeyCodec, or nil if there is none.
+func (m *SyncMap[K, V]) keyCodec() *jsonKeyCodec[K] {
+	if e := m.loadExtras(); e != nil {
+		return e.jsonKeys.Load()
+	}
+	return nil
+}
+
+// objectKey returns the JSON object key for key, using the map's key codec if one is set.
+func (m *SyncMap[K, V]) objectKey(key K) (string, error) {
+	if codec := m.keyCodec(); codec != nil {
+		return codec.keyToString(key), nil
+	}
+	return jsonKey(key)
+}
+
+// DumpJSON returns the map as an indented JSON string, for log lines and golden files.
+// Each nesting level is indented with indent. Keys are sorted, so the output is stable
+// for a given set of entries.
+func (m *SyncMap[K, V]) DumpJSON(indent string) (string, error) {
+	data, err := m.MarshalJSON()
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, data, "", indent); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// EncodeJSON streams the map to w as a JSON object, one entry at a time, without building
+// the whole document in memory. Keys are snapshotted and sorted by their JSON form first;
+// entries deleted after the snapshot are skipped. Keys follow the same rules as MarshalJSON.
+func (m *SyncMap[K, V]) EncodeJSON(w io.Writer) error {
+	m.lazyInit()
+	keys := m.Keys()
+	names := make(map[K]string, len(keys))
+	for _, key := range keys {
+		name, err := m.objectKey(key)
+		if err != nil {
+			return err
+		}
+		names[key] = name
+	}
+	slices.SortFunc(keys, func(a, b K) int {
+		return cmp.Compare(names[a], names[b])
+	})
+
+	if _, err := io.WriteString(w, "{"); err != nil {
+		return err
+	}
+	first := true
+	for _, key := range keys {
+		value, ok := m.LoadPresent(key)
+		if !ok {
+			continue
+		}
+		name, err := json.Marshal(names[key])
+		if err != nil {
+			return err
+		}
+		data, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		if !first {
+			if _, err := io.WriteString(w, ","); err != nil {
+				return err
+			}
+		}
+		first = false
+		if _, err := w.Write(name); err != nil {
+			return err
+		}
+		if _, err := io.WriteString(w, ":"); err != nil {
+			return err
+		}
+		if _, err := w.Write(data); err != nil {
+			return err
+		}
+	}
+	_, err := io.WriteString(w, "}")
+	return err
+}
+
+// jsonKey converts a map key to its JSON object key, following encoding/json:
+// string kinds are used as is, then encoding.TextMarshaler, then integers in decimal.
+func jsonKey(key any) (string, error) {
+	v := reflect.ValueOf(key)
+	if v.Kind() == reflect.String {
+		return v.String(), nil
+	}
+	if tm, ok := key.(encoding.TextMarshaler); ok {
+		text, err := tm.MarshalText()
+		return string(text), err
+	}
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(v.Uint(), 10), nil
+	}
+	return "", fmt.Errorf("asyncmap: unsupported JSON key type %T", key)
+}
+
+// jsonLine is one line of the JSON Lines format used by WriteJSONLines and ReadJSONLines.
+type jsonLine[K comparable, V any] struct {
+	Key   K `json:"key"`
+	Value V `json:"value"`
+}
+
+// WriteJSONLines writes every entry to w as a JSON Lines stream, one {"key":...,"value":...}
+// object per line. Keys are snapshotted and written in the order of their JSON encoding so the
+// output is deterministic; entries are then encoded one at a time, without buffering the whole map.
+// Entries deleted after the snapshot are skipped.
+func (m *SyncMap[K, V]) WriteJSONLines(w io.Writer) error {
+	keys := m.Keys()
+	encoded := make(map[K]string, len(keys))
+	for _, key := range keys {
+		data, err := json.Marshal(key)
+		if err != nil {
+			return err
+		}
+		encoded[key] = string(data)
+	}
+	slices.SortFunc(keys, func(a, b K) int {
+		return cmp.Compare(encoded[a], encoded[b])
+	})
+	enc := json.NewEncoder(w)
+	for _, key := range keys {
+		value, ok := m.LoadPresent(key)
+		if !ok {
+			continue
+		}
+		if err := enc.Encode(jsonLine[K, V]{Key: key, Value: value}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReadJSONLines builds a SyncMap from a JSON Lines stream of {"key":...,"value":...} objects,
+// as written by WriteJSONLines. Lines are read one at a time; blank lines are skipped and a
+// later line for the same key overwrites an earlier one. On a malformed line it returns the
+// entries read so far and an error naming the 1-based line number.
+func ReadJSONLines[K comparable, V any](r io.Reader) (SyncMap[K, V], error) {
+	out := NewSyncMap[K, V]()
+	reader := bufio.NewReader(r)
+	for lineNo := 1; ; lineNo++ {
+		line, readErr := reader.ReadBytes('\n')
+		if len(bytes.TrimSpace(line)) > 0 {
+			var entry jsonLine[K, V]
+			if err := json.Unmarshal(line, &entry); err != nil {
+				return out, fmt.Errorf("asyncmap: JSON Lines line %d: %w", lineNo, err)
+			}
+			out.Store(entry.Key, entry.Value)
+		}
+		if readErr == io.EOF {
+			return out, nil
+		}
+		if readErr != nil {
+			return out, fmt.Errorf("asyncmap: JSON Lines line %d: %w", lineNo, readErr)
+		}
+	}
+}
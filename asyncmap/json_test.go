@@ -0,0 +1,191 @@
+package asyncmap
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"strings"
+	"testing"
+)
+
+// config holds a SyncMap by value, as the marshalers' value receivers allow.
+type config struct {
+	Name   string
+	Limits SyncMap[string, int]
+}
+
+func TestJSONRoundTripByValue(t *testing.T) {
+	in := config{Name: "svc", Limits: NewSyncMap(map[string]int{"a": 1, "b": 2})}
+	data, err := json.Marshal(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"Name":"svc","Limits":{"a":1,"b":2}}`; string(data) != want {
+		t.Fatalf("Marshal = %s, want %s", data, want)
+	}
+	var out config
+	if err := json.Unmarshal(data, &out); err != nil {
+		t.Fatal(err)
+	}
+	if !maps.Equal(out.Limits.ToMap(), in.Limits.ToMap()) {
+		t.Fatalf("round trip = %v, want %v", out.Limits.ToMap(), in.Limits.ToMap())
+	}
+}
+
+func TestDumpJSONGolden(t *testing.T) {
+	m := NewSyncMap(map[string][]int{"zeta": {3}, "alpha": {1, 2}, "mid": nil})
+	got, err := m.DumpJSON("  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	const want = `{
+  "alpha": [
+    1,
+    2
+  ],
+  "mid": null,
+  "zeta": [
+    3
+  ]
+}`
+	if got != want {
+		t.Fatalf("DumpJSON =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestEncodeJSONDecodesBack(t *testing.T) {
+	m := NewSyncMap(map[int]string{10: "ten", 2: "two", -1: "minus one"})
+	var buf bytes.Buffer
+	if err := m.EncodeJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+	var decoded map[int]string
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("EncodeJSON wrote invalid JSON %s: %v", buf.Bytes(), err)
+	}
+	if !maps.Equal(decoded, m.ToMap()) {
+		t.Fatalf("decoded %v, want %v", decoded, m.ToMap())
+	}
+	buf.Reset()
+	empty := NewSyncMap[string, int]()
+	if err := empty.EncodeJSON(&buf); err != nil || buf.String() != "{}" {
+		t.Fatalf("EncodeJSON of an empty map = %q, %v", buf.String(), err)
+	}
+}
+
+func TestMarshalJSONIsByteIdentical(t *testing.T) {
+	m := NewSyncMap[int, string]()
+	for i := 20; i > 0; i-- {
+		m.Store(i*37%101, "v")
+	}
+	first, err := m.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 10; i++ {
+		again, err := json.Marshal(m)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(first, again) {
+			t.Fatalf("marshal %d = %s, want %s", i, again, first)
+		}
+	}
+}
+
+func TestWriteJSONLinesParsesBack(t *testing.T) {
+	m := NewSyncMap(map[string]int{"b": 2, "a": 1, "c": 3})
+	var buf bytes.Buffer
+	if err := m.WriteJSONLines(&buf); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 3 || lines[0] != `{"key":"a","value":1}` {
+		t.Fatalf("WriteJSONLines wrote %q", buf.String())
+	}
+	back, err := ReadJSONLines[string, int](&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !maps.Equal(back.ToMap(), m.ToMap()) {
+		t.Fatalf("parsed back %v, want %v", back.ToMap(), m.ToMap())
+	}
+}
+
+func TestReadJSONLinesReportsMalformedLine(t *testing.T) {
+	input := `{"key":"a","value":1}
+
+{"key":"b","value":2}
+{"key":"c","value":
+{"key":"d","value":4}
+`
+	m, err := ReadJSONLines[string, int](strings.NewReader(input))
+	if err == nil || !strings.Contains(err.Error(), "line 4") {
+		t.Fatalf("ReadJSONLines error = %v, want one naming line 4", err)
+	}
+	if want := map[string]int{"a": 1, "b": 2}; !maps.Equal(m.ToMap(), want) {
+		t.Fatalf("entries read before the error = %v, want %v", m.ToMap(), want)
+	}
+}
+
+// state is an enum-like key written to JSON by name through SetJSONKeyCodec.
+type state int
+
+const (
+	stateIdle state = iota
+	stateRunning
+)
+
+var stateNames = []string{"idle", "running"}
+
+func (s state) String() string { return stateNames[s] }
+
+func parseState(name string) (state, error) {
+	for i, n := range stateNames {
+		if n == name {
+			return state(i), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown state %q", name)
+}
+
+func TestJSONKeyCodecEnumRoundTrip(t *testing.T) {
+	m := NewSyncMap(map[state]int{stateIdle: 3, stateRunning: 5})
+	m.SetJSONKeyCodec(state.String, parseState)
+	data, err := json.Marshal(&m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"idle":3,"running":5}`; string(data) != want {
+		t.Fatalf("Marshal = %s, want %s", data, want)
+	}
+	back := NewSyncMap[state, int]()
+	back.SetJSONKeyCodec(state.String, parseState)
+	if err := json.Unmarshal(data, &back); err != nil {
+		t.Fatal(err)
+	}
+	if !maps.Equal(back.ToMap(), m.ToMap()) {
+		t.Fatalf("round trip = %v, want %v", back.ToMap(), m.ToMap())
+	}
+	if err := json.Unmarshal([]byte(`{"paused":1}`), &back); err == nil {
+		t.Fatal("Unmarshal accepted an unknown enum name")
+	}
+}
+
+func TestMergeJSON(t *testing.T) {
+	m := NewSyncMap(map[string]int{"keep": 1, "update": 2})
+	if err := MergeJSON(&m, []byte(`{"update":20,"add":30}`)); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]int{"keep": 1, "update": 20, "add": 30}
+	if !maps.Equal(m.ToMap(), want) {
+		t.Fatalf("after MergeJSON = %v, want %v", m.ToMap(), want)
+	}
+	if err := MergeJSON(&m, []byte(`{"update":99,"add":`)); err == nil {
+		t.Fatal("MergeJSON accepted malformed JSON")
+	}
+	if !maps.Equal(m.ToMap(), want) {
+		t.Fatalf("malformed MergeJSON changed the map to %v", m.ToMap())
+	}
+}
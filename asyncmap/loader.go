@@ -0,0 +1,173 @@
+package asyncmap
+
+import (
+	"context"
+	"errors"
+)
+
+// errLoaderPanicked is returned to callers waiting on a load whose loader panicked.
+var errLoaderPanicked = errors.New("asyncmap: loader panicked")
+
+// flight is a load in progress, shared by every caller that missed the same key.
+type flight[V any] struct {
+	done  chan struct{}
+	value V
+	err   error
+}
+
+// GetOrLoad returns the value for key if present, counting a stored nil as present as
+// LoadPresent does. Otherwise it calls loader, stores the
+// result and returns it. Concurrent misses for the same key share a single loader call:
+// the first caller runs loader with its ctx, and the others wait for that result.
+// A loader error is returned to every waiting caller and nothing is stored.
+// A waiting caller whose ctx is done stops waiting and returns ctx.Err() while the load
+// carries on for the others. The first caller's ctx is the one loader sees, though, so if
+// that ctx is cancelled and loader gives up, its error is what every waiter receives.
+func (m *SyncMap[K, V]) GetOrLoad(ctx context.Context, key K, loader func(ctx context.Context, key K) (V, error)) (V, error) {
+	return m.getOrLoad(ctx, key, loader, func(_ V, ok bool) bool { return ok })
+}
+
+// getOrLoad implements GetOrLoad, with hit deciding whether the value found by LoadPresent
+// can be returned or must be loaded.
+func (m *SyncMap[K, V]) getOrLoad(ctx context.Context, key K, loader func(ctx context.Context, key K) (V, error), hit func(value V, ok bool) bool) (V, error) {
+	m.lazyInit()
+	if value, ok := m.LoadPresent(key); hit(value, ok) {
+		return value, nil
+	}
+	f := &flight[V]{done: make(chan struct{})}
+	flights := &m.initExtras().flights
+	if inFlight, loaded := flights.LoadOrStore(key, f); loaded {
+		f = inFlight.(*flight[V])
+		select {
+		case <-f.done:
+			return f.value, f.err
+		case <-ctx.Done():
+			var zero V
+			return zero, ctx.Err()
+		}
+	}
+	defer func() {
+		flights.Delete(key)
+		close(f.done)
+	}()
+	// Another load may have finished between our miss and registering the flight.
+	if value, ok := m.LoadPresent(key); hit(value, ok) {
+		f.value = value
+		return f.value, nil
+	}
+	f.err = errLoaderPanicked
+	f.value, f.err = loader(ctx, key)
+	if f.err == nil {
+		m.Store(key, f.value)
+	}
+	return f.value, f.err
+}
+
+// Loader is a backing store consulted by ReadThrough on a cache miss.
+// Load reports ok=false when the key does not exist in the backing store.
+type Loader[K comparable, V any] interface {
+	Load(key K) (value V, ok bool, err error)
+}
+
+// ReadThrough is a cache front-end over a Loader. Hits are served from an internal SyncMap;
+// misses are loaded from the backing store and cached.
+type ReadThrough[K comparable, V any] struct {
+	cache    SyncMap[K, V]
+	notFound SyncMap[K, struct{}]
+	loader   Loader[K, V]
+	negative bool
+}
+
+// NewReadThrough creates a ReadThrough cache over loader. When cacheNotFound is true, keys the
+// loader reports as not found are remembered too (negative caching), so repeated lookups of a
+// missing key don't reach the backing store; otherwise only found values are cached.
+func NewReadThrough[K comparable, V any](loader Loader[K, V], cacheNotFound bool) *ReadThrough[K, V] {
+	return &ReadThrough[K, V]{
+		cache:    NewSyncMap[K, V](),
+		notFound: NewSyncMap[K, struct{}](),
+		loader:   loader,
+		negative: cacheNotFound,
+	}
+}
+
+// Get returns the value for key, consulting the backing store on a miss and caching the result.
+// ok is false when the key does not exist. Loader errors are returned as is and nothing is cached.
+func (r *ReadThrough[K, V]) Get(key K) (value V, ok bool, err error) {
+	if value, ok := r.cache.Load(key); ok {
+		return value, true, nil
+	}
+	if r.negative && r.notFound.Has(key) {
+		return value, false, nil
+	}
+	value, ok, err = r.loader.Load(key)
+	if err != nil {
+		return value, false, err
+	}
+	if ok {
+		r.cache.Store(key, value)
+	} else if r.negative {
+		r.notFound.Store(key, struct{}{})
+	}
+	return value, ok, nil
+}
+
+// Invalidate drops any cached value or cached not-found result for key,
+// so the next Get consults the backing store again.
+func (r *ReadThrough[K, V]) Invalidate(key K) {
+	r.cache.Delete(key)
+	r.notFound.Delete(key)
+}
+
+// EnsureKeys makes sure every key in keys is present, batching misses into a single backing call.
+// It works out which keys are missing (under the same rules as Load), calls loader once with
+// those keys, deduplicated and in request order, and stores the values it returns. Keys already
+// present are never overwritten, including keys written concurrently while loader runs.
+// loader is not called when nothing is missing. A loader error is returned and nothing is stored.
+func (m *SyncMap[K, V]) EnsureKeys(keys []K, loader func(missing []K) (map[K]V, error)) error {
+	_, missing := m.GetAll(keys...)
+	if len(missing) == 0 {
+		return nil
+	}
+	seen := make(map[K]struct{}, len(missing))
+	unique := missing[:0]
+	for _, key := range missing {
+		if _, dup := seen[key]; !dup {
+			seen[key] = struct{}{}
+			unique = append(unique, key)
+		}
+	}
+	loaded, err := loader(unique)
+	if err != nil {
+		return err
+	}
+	for key, value := range loaded {
+		m.LoadOrStore(key, value)
+	}
+	return nil
+}
+
+// Memoize returns a memoizing version of fn backed by an internal SyncMap. Each distinct
+// input is computed once; concurrent calls with the same uncached input share a single call
+// to fn, via GetOrLoad. Results are kept for the life of the returned function.
+func Memoize[K comparable, V any](fn func(K) V) func(K) V {
+	cache := NewSyncMap[K, V]()
+	load := func(_ context.Context, key K) (V, error) {
+		return fn(key), nil
+	}
+	return func(key K) V {
+		value, _ := cache.GetOrLoad(context.Background(), key, load)
+		return value
+	}
+}
+
+// LoadOrStorePtr returns the pointer stored for key, or stores and returns one made by new
+// if key is absent or holds a nil pointer: the get-or-create pattern for shared objects.
+// Concurrent callers that miss the same key share a single call to new, so they all receive
+// the same pointer.
+func LoadOrStorePtr[K comparable, T any](m *SyncMap[K, *T], key K, new func() *T) *T {
+	construct := func(context.Context, K) (*T, error) {
+		return new(), nil
+	}
+	ptr, _ := m.getOrLoad(context.Background(), key, construct, func(ptr *T, _ bool) bool { return ptr != nil })
+	return ptr
+}
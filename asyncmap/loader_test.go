@@ -0,0 +1,298 @@
+package asyncmap
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestMemoizeCachesNilInterfaceResults(t *testing.T) {
+	calls := 0
+	check := Memoize(func(n int) error {
+		calls++
+		return nil
+	})
+	for i := 0; i < 3; i++ {
+		if err := check(7); err != nil {
+			t.Fatalf("check(7) = %v", err)
+		}
+	}
+	if calls != 1 {
+		t.Fatalf("fn called %d times, want 1", calls)
+	}
+}
+
+func TestLoadOrStorePtrSharesOnePointer(t *testing.T) {
+	type conn struct{ id int }
+	m := NewSyncMap[string, *conn]()
+	var calls atomic.Int32
+	start := make(chan struct{})
+	results := make([]*conn, 32)
+	var wg sync.WaitGroup
+	for i := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			results[i] = LoadOrStorePtr(&m, "db", func() *conn {
+				calls.Add(1)
+				time.Sleep(time.Millisecond)
+				return &conn{id: 1}
+			})
+		}()
+	}
+	close(start)
+	wg.Wait()
+	if calls.Load() != 1 {
+		t.Fatalf("new called %d times, want 1", calls.Load())
+	}
+	for i, got := range results {
+		if got == nil || got != results[0] {
+			t.Fatalf("caller %d got %p, want %p", i, got, results[0])
+		}
+	}
+}
+
+func TestLoadOrStorePtrReplacesStoredNil(t *testing.T) {
+	m := NewSyncMap[string, *int]()
+	m.Store("k", nil)
+	n := 5
+	if got := LoadOrStorePtr(&m, "k", func() *int { return &n }); got != &n {
+		t.Fatalf("LoadOrStorePtr = %v, want the new pointer", got)
+	}
+	if got := m.Get("k"); got != &n {
+		t.Fatal("new pointer was not stored")
+	}
+}
+
+// countingLoader is a Loader over a fixed map that counts its calls.
+type countingLoader struct {
+	data  map[string]int
+	calls map[string]int
+	err   error
+}
+
+func (l *countingLoader) Load(key string) (int, bool, error) {
+	l.calls[key]++
+	if l.err != nil {
+		return 0, false, l.err
+	}
+	value, ok := l.data[key]
+	return value, ok, nil
+}
+
+func TestReadThroughCachesHits(t *testing.T) {
+	loader := &countingLoader{data: map[string]int{"a": 1}, calls: map[string]int{}}
+	cache := NewReadThrough[string, int](loader, false)
+	for i := 0; i < 3; i++ {
+		if value, ok, err := cache.Get("a"); value != 1 || !ok || err != nil {
+			t.Fatalf("Get(a) = (%d, %v, %v)", value, ok, err)
+		}
+		if _, ok, _ := cache.Get("missing"); ok {
+			t.Fatal("Get(missing) reported found")
+		}
+	}
+	if loader.calls["a"] != 1 || loader.calls["missing"] != 3 {
+		t.Fatalf("loader calls = %v, want a:1 missing:3", loader.calls)
+	}
+	cache.Invalidate("a")
+	cache.Get("a")
+	if loader.calls["a"] != 2 {
+		t.Fatalf("loader calls for a = %d after Invalidate, want 2", loader.calls["a"])
+	}
+}
+
+func TestReadThroughNegativeCaching(t *testing.T) {
+	loader := &countingLoader{data: map[string]int{}, calls: map[string]int{}}
+	cache := NewReadThrough[string, int](loader, true)
+	cache.Get("missing")
+	cache.Get("missing")
+	if loader.calls["missing"] != 1 {
+		t.Fatalf("loader calls = %d with negative caching, want 1", loader.calls["missing"])
+	}
+	loader.data["missing"] = 5
+	cache.Invalidate("missing")
+	if value, ok, _ := cache.Get("missing"); !ok || value != 5 {
+		t.Fatalf("Get after Invalidate = (%d, %v), want (5, true)", value, ok)
+	}
+}
+
+func TestReadThroughDoesNotCacheErrors(t *testing.T) {
+	errDown := errors.New("backend down")
+	loader := &countingLoader{data: map[string]int{"a": 1}, calls: map[string]int{}, err: errDown}
+	cache := NewReadThrough[string, int](loader, true)
+	if _, _, err := cache.Get("a"); err != errDown {
+		t.Fatalf("Get error = %v, want errDown", err)
+	}
+	loader.err = nil
+	if value, ok, err := cache.Get("a"); value != 1 || !ok || err != nil {
+		t.Fatalf("Get after recovery = (%d, %v, %v)", value, ok, err)
+	}
+}
+
+func TestEnsureKeysLoadsExactlyMissing(t *testing.T) {
+	m := NewSyncMap(map[string]int{"a": 1, "c": 3})
+	var requested [][]string
+	loader := func(missing []string) (map[string]int, error) {
+		requested = append(requested, append([]string(nil), missing...))
+		out := make(map[string]int)
+		for _, key := range missing {
+			out[key] = len(key) * 10
+		}
+		out["a"] = 100 // present keys are never overwritten
+		return out, nil
+	}
+	if err := m.EnsureKeys([]string{"a", "bb", "c", "dd", "bb"}, loader); err != nil {
+		t.Fatal(err)
+	}
+	if len(requested) != 1 || !slices.Equal(requested[0], []string{"bb", "dd"}) {
+		t.Fatalf("loader requests = %v, want [[bb dd]]", requested)
+	}
+	if want := map[string]int{"a": 1, "bb": 20, "c": 3, "dd": 20}; !maps.Equal(m.ToMap(), want) {
+		t.Fatalf("after EnsureKeys = %v, want %v", m.ToMap(), want)
+	}
+	if err := m.EnsureKeys([]string{"a", "bb"}, loader); err != nil || len(requested) != 1 {
+		t.Fatalf("EnsureKeys with nothing missing = %v after %d loader calls, want no call", err, len(requested))
+	}
+	errDown := errors.New("backend down")
+	err := m.EnsureKeys([]string{"e"}, func([]string) (map[string]int, error) {
+		return map[string]int{"e": 5}, errDown
+	})
+	if err != errDown || m.Has("e") {
+		t.Fatalf("EnsureKeys error = %v, Has(e) = %v; want errDown and nothing stored", err, m.Has("e"))
+	}
+}
+
+func TestGetOrLoadRunsLoaderOnce(t *testing.T) {
+	m := NewSyncMap[string, *int]()
+	var calls atomic.Int32
+	loader := func(context.Context, string) (*int, error) {
+		calls.Add(1)
+		time.Sleep(20 * time.Millisecond)
+		return new(int), nil
+	}
+	const callers = 16
+	start := make(chan struct{})
+	results := make([]*int, callers)
+	var wg sync.WaitGroup
+	for i := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			value, err := m.GetOrLoad(context.Background(), "k", loader)
+			if err != nil {
+				t.Errorf("GetOrLoad = %v", err)
+			}
+			results[i] = value
+		}()
+	}
+	close(start)
+	wg.Wait()
+	if calls.Load() != 1 {
+		t.Fatalf("loader ran %d times for %d simultaneous callers, want 1", calls.Load(), callers)
+	}
+	for i, value := range results {
+		if value == nil || value != results[0] {
+			t.Fatalf("caller %d got %p, want the shared result %p", i, value, results[0])
+		}
+	}
+}
+
+func TestGetOrLoadPropagatesLoaderError(t *testing.T) {
+	m := NewSyncMap[string, int]()
+	errDown := errors.New("backend down")
+	release := make(chan struct{})
+	loader := func(context.Context, string) (int, error) {
+		<-release
+		return 0, errDown
+	}
+	errs := make(chan error, 2)
+	go func() {
+		_, err := m.GetOrLoad(context.Background(), "k", loader)
+		errs <- err
+	}()
+	waitForFlight(t, &m, "k")
+	go func() {
+		_, err := m.GetOrLoad(context.Background(), "k", loader)
+		errs <- err
+	}()
+	close(release)
+	for i := 0; i < 2; i++ {
+		if err := <-errs; err != errDown {
+			t.Fatalf("GetOrLoad error = %v, want errDown", err)
+		}
+	}
+	if m.Has("k") {
+		t.Fatal("a failed load stored a value")
+	}
+}
+
+func TestGetOrLoadWaiterCancelled(t *testing.T) {
+	m := NewSyncMap[string, int]()
+	release := make(chan struct{})
+	loader := func(context.Context, string) (int, error) {
+		<-release
+		return 42, nil
+	}
+	leader := make(chan int)
+	go func() {
+		value, _ := m.GetOrLoad(context.Background(), "k", loader)
+		leader <- value
+	}()
+	waitForFlight(t, &m, "k")
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := m.GetOrLoad(ctx, "k", loader); err != context.Canceled {
+		t.Fatalf("cancelled waiter got %v, want context.Canceled", err)
+	}
+	close(release)
+	if value := <-leader; value != 42 || m.Get("k") != 42 {
+		t.Fatalf("load after a waiter gave up = %d, stored %d; want 42", value, m.Get("k"))
+	}
+}
+
+// waitForFlight waits until a GetOrLoad call for key has registered its load.
+func waitForFlight[K comparable, V any](t *testing.T, m *SyncMap[K, V], key K) {
+	t.Helper()
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(time.Millisecond) {
+		if _, ok := m.initExtras().flights.Load(key); ok {
+			return
+		}
+	}
+	t.Fatal("GetOrLoad never started loading")
+}
+
+func TestMemoizeConcurrentCallsOncePerKey(t *testing.T) {
+	var calls [4]atomic.Int32
+	square := Memoize(func(n int) int {
+		calls[n].Add(1)
+		time.Sleep(5 * time.Millisecond)
+		return n * n
+	})
+	start := make(chan struct{})
+	var wg sync.WaitGroup
+	for g := 0; g < 32; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			n := g % len(calls)
+			if got := square(n); got != n*n {
+				t.Errorf("square(%d) = %d", n, got)
+			}
+		}()
+	}
+	close(start)
+	wg.Wait()
+	for n := range calls {
+		if got := calls[n].Load(); got != 1 {
+			t.Errorf("fn ran %d times for key %d, want 1", got, n)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package asyncmap
+
+import (
+	"log"
+	"log/slog"
+	"sync/atomic"
+)
+
+// silent is set by SetSilentMode.
+var silent atomic.Bool
+
+// slogLogger is set by SetSlogLogger.
+var slogLogger atomic.Pointer[slog.Logger]
+
+// SetSilentMode turns off all of the package's internal logging (recovered panics and
+// failed type assertions during Range) when on is true, for libraries that embed this
+// package and can't tolerate log noise. It is off by default. It is safe to call concurrently.
+func SetSilentMode(on bool) {
+	silent.Store(on)
+}
+
+// SetSlogLogger routes the package's internal logging to logger as structured records,
+// with the recovered panic value and the offending key as attributes. Passing nil restores
+// the default of logging through the standard log package. Silent mode still applies.
+func SetSlogLogger(logger *slog.Logger) {
+	slogLogger.Store(logger)
+}
+
+// logRangePanic logs a panic recovered from a Range callback.
+func logRangePanic(recovered, key any) {
+	if silent.Load() {
+		return
+	}
+	if logger := slogLogger.Load(); logger != nil {
+		logger.Error("SyncMap Range panic recovered", "recovered", recovered, "key", key)
+		return
+	}
+	log.Printf("SyncMap Range Panic (Recovered): %+v", recovered)
+}
+
+// logRangeAssertion logs an entry skipped by Range because its key or value had the wrong type.
+func logRangeAssertion(key any) {
+	if silent.Load() {
+		return
+	}
+	if logger := slogLogger.Load(); logger != nil {
+		logger.Warn("SyncMap Range assertion failed", "key", key)
+		return
+	}
+	log.Printf("SyncMap: Range assertion failed for key: %+v", key)
+}
@@ -0,0 +1,53 @@
+package asyncmap
+
+import (
+	"bytes"
+	"log"
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestSilentModeSuppressesLogs(t *testing.T) {
+	var logs bytes.Buffer
+	log.SetOutput(&logs)
+	defer log.SetOutput(os.Stderr)
+	SetSilentMode(true)
+	defer SetSilentMode(false)
+
+	m := NewSyncMap(map[string]int{"a": 1})
+	m.Unwrap().Store("bad", "not an int")
+	m.Range(func(string, int) bool { panic("boom") })
+	if logs.Len() != 0 {
+		t.Fatalf("silent mode logged %q", logs.String())
+	}
+
+	SetSilentMode(false)
+	m.Range(func(string, int) bool { panic("boom") })
+	if logs.Len() == 0 {
+		t.Fatal("nothing logged after silent mode was turned off")
+	}
+}
+
+func TestSlogLoggerReceivesAttributes(t *testing.T) {
+	var out bytes.Buffer
+	SetSlogLogger(slog.New(slog.NewTextHandler(&out, nil)))
+	defer SetSlogLogger(nil)
+
+	m := NewSyncMap(map[string]int{"hot": 1})
+	m.Range(func(string, int) bool { panic("boom") })
+	record := out.String()
+	for _, want := range []string{"level=ERROR", "recovered=boom", "key=hot"} {
+		if !strings.Contains(record, want) {
+			t.Fatalf("slog record %q is missing %s", record, want)
+		}
+	}
+
+	out.Reset()
+	m.Unwrap().Store("bad", "not an int")
+	m.Range(func(string, int) bool { return true })
+	if record := out.String(); !strings.Contains(record, "level=WARN") || !strings.Contains(record, "key=bad") {
+		t.Fatalf("assertion record = %q, want a WARN with key=bad", record)
+	}
+}
@@ -0,0 +1,20 @@
+package asyncmap
+
+// StoreWithMeta stores value under key, as Store does, and attaches meta to the entry, for
+// annotations such as a creation time or source that shouldn't be folded into V.
+// The metadata lives alongside the entry: it is removed when the entry is deleted (by Delete,
+// Clear or any other removal), while a later plain Store of the same key keeps it.
+// The metadata is attached before the value is stored, so a concurrent Delete of the same key
+// can drop it but never leaves it behind without an entry.
+func (m *SyncMap[K, V]) StoreWithMeta(key K, value V, meta any) {
+	m.lazyInit()
+	m.mustBeWritable()
+	m.storeMeta(key, meta)
+	m.Store(key, value)
+}
+
+// LoadMeta returns the metadata attached to key with StoreWithMeta and whether there is any.
+func (m *SyncMap[K, V]) LoadMeta(key K) (any, bool) {
+	m.lazyInit()
+	return m.loadMeta(key)
+}
@@ -0,0 +1,34 @@
+package asyncmap
+
+import "testing"
+
+func TestStoreWithMetaRemovedOnDelete(t *testing.T) {
+	m := NewSyncMap[string, int]()
+	m.StoreWithMeta("a", 1, "etag-1")
+	if meta, ok := m.LoadMeta("a"); !ok || meta != "etag-1" || m.Get("a") != 1 {
+		t.Fatalf("LoadMeta(a) = (%v, %v) with value %d", meta, ok, m.Get("a"))
+	}
+	m.StoreWithMeta("a", 2, "etag-2")
+	if meta, _ := m.LoadMeta("a"); meta != "etag-2" {
+		t.Fatalf("LoadMeta after overwrite = %v, want etag-2", meta)
+	}
+	m.Store("a", 3)
+	if meta, _ := m.LoadMeta("a"); meta != "etag-2" {
+		t.Fatalf("LoadMeta after a plain Store = %v, want etag-2 kept", meta)
+	}
+	m.Delete("a")
+	if meta, ok := m.LoadMeta("a"); ok {
+		t.Fatalf("LoadMeta after Delete = %v, want none", meta)
+	}
+	m.StoreWithMeta("b", 1, "x")
+	m.LoadAndDelete("b")
+	m.Store("b", 2)
+	if _, ok := m.LoadMeta("b"); ok {
+		t.Fatal("metadata survived LoadAndDelete and came back with a new value")
+	}
+	m.StoreWithMeta("c", 1, "x")
+	m.Clear()
+	if _, ok := m.LoadMeta("c"); ok {
+		t.Fatal("metadata survived Clear")
+	}
+}
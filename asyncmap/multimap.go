@@ -0,0 +1,41 @@
+package asyncmap
+
+import "slices"
+
+// AppendTo atomically appends elems to the slice stored under key, creating it if absent.
+// This is the multimap insert: it holds the local lock, so concurrent appends to the same key
+// are never lost. The stored slice is always replaced by a new one, so slices previously
+// returned by Load are never modified.
+func AppendTo[K comparable, E any](m *SyncMap[K, []E], key K, elems ...E) {
+	m.lazyInit()
+	m.mustBeWritable()
+	m.core.localLock.Lock()
+	defer m.core.localLock.Unlock()
+	current, _ := m.lookup(key)
+	m.Store(key, append(slices.Clip(current), elems...))
+}
+
+// RemoveFrom atomically removes the first occurrence of elem from the slice stored under key
+// and reports whether anything was removed. When the slice becomes empty the key is deleted.
+// It holds the local lock, so it serializes with AppendTo. Like AppendTo, it stores a new
+// slice rather than modifying the existing one.
+func RemoveFrom[K comparable, E comparable](m *SyncMap[K, []E], key K, elem E) bool {
+	m.lazyInit()
+	m.mustBeWritable()
+	m.core.localLock.Lock()
+	defer m.core.localLock.Unlock()
+	current, ok := m.lookup(key)
+	if !ok {
+		return false
+	}
+	i := slices.Index(current, elem)
+	if i < 0 {
+		return false
+	}
+	if len(current) == 1 {
+		m.Delete(key)
+		return true
+	}
+	m.Store(key, slices.Concat(current[:i], current[i+1:]))
+	return true
+}
@@ -0,0 +1,51 @@
+package asyncmap
+
+import (
+	"slices"
+	"sync"
+	"testing"
+)
+
+func TestAppendToConcurrent(t *testing.T) {
+	m := NewSyncMap[string, []int]()
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				AppendTo(&m, "k", g*100+i)
+			}
+		}()
+	}
+	wg.Wait()
+	got := slices.Clone(m.Get("k"))
+	slices.Sort(got)
+	if len(got) != 800 {
+		t.Fatalf("AppendTo kept %d elements, want 800", len(got))
+	}
+	for i, elem := range got {
+		if elem != i {
+			t.Fatalf("element %d = %d; appends were lost or duplicated", i, elem)
+		}
+	}
+}
+
+func TestRemoveFrom(t *testing.T) {
+	m := NewSyncMap[string, []int]()
+	AppendTo(&m, "k", 1, 2, 1)
+	before := m.Get("k")
+	if !RemoveFrom(&m, "k", 1) {
+		t.Fatal("RemoveFrom of a present element reported false")
+	}
+	if got := m.Get("k"); !slices.Equal(got, []int{2, 1}) || !slices.Equal(before, []int{1, 2, 1}) {
+		t.Fatalf("after RemoveFrom = %v (earlier slice now %v), want [2 1] and an untouched earlier slice", got, before)
+	}
+	if RemoveFrom(&m, "k", 9) || RemoveFrom(&m, "absent", 1) {
+		t.Fatal("RemoveFrom of a missing element reported true")
+	}
+	RemoveFrom(&m, "k", 2)
+	if !RemoveFrom(&m, "k", 1) || m.Has("k") {
+		t.Fatal("removing the last element did not delete the key")
+	}
+}
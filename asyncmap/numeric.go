@@ -0,0 +1,86 @@
+package asyncmap
+
+import "slices"
+
+// Number is the set of built-in numeric types, and types derived from them,
+// accepted by the numeric helpers in this package.
+type Number interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr |
+		~float32 | ~float64
+}
+
+// ClampValues rewrites every value in m into the range [lo, hi] in place.
+// Values already in range are left untouched. It holds the local lock for its single pass,
+// so it is atomic with respect to Range, Clear and other composite operations.
+func ClampValues[K comparable, V Number](m *SyncMap[K, V], lo, hi V) {
+	m.lazyInit()
+	m.mustBeWritable()
+	m.core.localLock.Lock()
+	defer m.core.localLock.Unlock()
+	m.rangeRaw(func(key, value any) bool {
+		typedKey, typedKeyOk := m.typedKey(key)
+		typedValue, typedValueOk := m.typedValue(value)
+		if !typedKeyOk || !typedValueOk {
+			return true
+		}
+		if typedValue < lo {
+			m.store(typedKey, lo)
+		} else if typedValue > hi {
+			m.store(typedKey, hi)
+		}
+		return true
+	})
+}
+
+// FetchAdd adds delta to the value stored for key and returns the value from before the
+// addition. An absent key is treated as zero, so the first call stores delta and returns 0.
+// Updates are serialized by the local lock, which makes FetchAdd suitable for handing
+// out per-key sequence numbers.
+func FetchAdd[K comparable, V Number](m *SyncMap[K, V], key K, delta V) (old V) {
+	m.lazyInit()
+	m.mustBeWritable()
+	m.core.localLock.Lock()
+	defer m.core.localLock.Unlock()
+	old, _ = m.lookup(key)
+	m.Store(key, old+delta)
+	return old
+}
+
+// IncrCapped adds delta to the value stored for key, clamping the result to limit, and stores
+// and returns the clamped value. An absent key is treated as zero. Like FetchAdd, updates are
+// serialized by the local lock, so concurrent increments never push the value past limit.
+func IncrCapped[K comparable, V Number](m *SyncMap[K, V], key K, delta, limit V) V {
+	m.lazyInit()
+	m.mustBeWritable()
+	m.core.localLock.Lock()
+	defer m.core.localLock.Unlock()
+	current, _ := m.lookup(key)
+	next := min(current+delta, limit)
+	m.Store(key, next)
+	return next
+}
+
+// Histogram counts the values of m into buckets delimited by the sorted edges in buckets,
+// returning the count per bucket index. Bucket i holds values in [buckets[i], buckets[i+1]);
+// the last bucket holds every value at or above the last edge, and values below the first
+// edge are counted in bucket 0. Buckets with no values are absent from the result.
+// It iterates the map once.
+func Histogram[K comparable, V Number](m SyncMap[K, V], buckets []V) map[int]int {
+	counts := make(map[int]int)
+	if len(buckets) == 0 {
+		return counts
+	}
+	m.Range(func(_ K, value V) bool {
+		// Index of the first edge greater than value; the bucket is the one before it.
+		i, _ := slices.BinarySearchFunc(buckets, value, func(edge, target V) int {
+			if edge <= target {
+				return -1
+			}
+			return 1
+		})
+		counts[max(i-1, 0)]++
+		return true
+	})
+	return counts
+}
@@ -0,0 +1,98 @@
+package asyncmap
+
+import (
+	"maps"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestClampValues(t *testing.T) {
+	m := NewSyncMap(map[string]int{"low": -5, "high": 50, "in": 7, "edge": 10})
+	_, before, _ := m.LoadVersioned("in")
+	ClampValues(&m, 0, 10)
+	if _, after, _ := m.LoadVersioned("in"); after != before {
+		t.Fatal("ClampValues rewrote a value already in range")
+	}
+	want := map[string]int{"low": 0, "high": 10, "in": 7, "edge": 10}
+	if got := m.ToMap(); !maps.Equal(got, want) {
+		t.Fatalf("ClampValues left %v, want %v", got, want)
+	}
+}
+
+func TestIncrCappedConcurrent(t *testing.T) {
+	m := NewSyncMap[string, int]()
+	var wg sync.WaitGroup
+	var exceeded atomic.Bool
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				if IncrCapped(&m, "retries", 1, 50) > 50 {
+					exceeded.Store(true)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if exceeded.Load() {
+		t.Fatal("IncrCapped returned a value above the cap")
+	}
+	if got := m.Get("retries"); got != 50 {
+		t.Fatalf("retries = %d, want 50", got)
+	}
+	if got := IncrCapped(&m, "fresh", 3, 50); got != 3 {
+		t.Fatalf("IncrCapped on an absent key = %d, want 3", got)
+	}
+}
+
+func TestFetchAddReturnsUniqueOldValues(t *testing.T) {
+	m := NewSyncMap[string, int]()
+	const goroutines, perGoroutine = 8, 200
+	olds := make([][]int, goroutines)
+	var wg sync.WaitGroup
+	for g := range olds {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < perGoroutine; i++ {
+				olds[g] = append(olds[g], FetchAdd(&m, "ticket", 1))
+			}
+		}()
+	}
+	wg.Wait()
+	seen := make(map[int]bool)
+	for _, list := range olds {
+		for _, old := range list {
+			if seen[old] {
+				t.Fatalf("FetchAdd returned old value %d twice", old)
+			}
+			seen[old] = true
+		}
+	}
+	if len(seen) != goroutines*perGoroutine || m.Get("ticket") != goroutines*perGoroutine {
+		t.Fatalf("got %d distinct old values and final %d, want %d", len(seen), m.Get("ticket"), goroutines*perGoroutine)
+	}
+}
+
+func TestHistogram(t *testing.T) {
+	m := NewSyncMap(map[string]float64{
+		"below": -3, "first": 0, "mid": 5, "edge": 10, "high": 19.5, "beyond": 1000,
+	})
+	got := Histogram(m, []float64{0, 10, 20})
+	if want := map[int]int{0: 3, 1: 2, 2: 1}; !maps.Equal(got, want) {
+		t.Fatalf("Histogram = %v, want %v", got, want)
+	}
+	uniform := NewSyncMap[int, int]()
+	for i := 0; i < 100; i++ {
+		uniform.Store(i, i)
+	}
+	got = Histogram(uniform, []int{0, 25, 50, 75})
+	if want := map[int]int{0: 25, 1: 25, 2: 25, 3: 25}; !maps.Equal(got, want) {
+		t.Fatalf("Histogram of 0..99 = %v, want %v", got, want)
+	}
+	if got := Histogram(uniform, nil); len(got) != 0 {
+		t.Fatalf("Histogram with no buckets = %v, want empty", got)
+	}
+}
@@ -0,0 +1,57 @@
+package asyncmap
+
+// PanicPolicy selects how Range and its variants handle a panic raised by the
+// user-supplied callback.
+type PanicPolicy int
+
+const (
+	// PanicRecover recovers the panic, logs it, and continues the iteration. It is the default.
+	PanicRecover PanicPolicy = iota
+	// PanicPropagate lets the panic propagate to the caller. The local lock is still
+	// released on the way out, so the map stays usable.
+	PanicPropagate
+	// PanicCallback recovers the panic, passes it to the handler given to SetPanicPolicy
+	// along with the entry being visited, and continues the iteration.
+	PanicCallback
+)
+
+// panicPolicy is a policy together with its PanicCallback handler.
+type panicPolicy[K comparable, V any] struct {
+	policy  PanicPolicy
+	handler func(recovered any, key K, value V)
+}
+
+// SetPanicPolicy sets how Range and its variants (RangeErr, RangeSnapshot, RangeRandom, ...)
+// handle a panic in the user-supplied callback. PanicCallback requires a handler; without one
+// it behaves like PanicRecover.
+func (m *SyncMap[K, V]) SetPanicPolicy(policy PanicPolicy, handler ...func(recovered any, key K, value V)) {
+	m.lazyInit()
+	p := &panicPolicy[K, V]{policy: policy}
+	if len(handler) > 0 {
+		p.handler = handler[0]
+	}
+	m.initExtras().panics.Store(p)
+}
+
+// callSafely calls fn for one entry, handling a panic according to the map's panic policy.
+// A recovered panic lets the iteration continue.
+func (m *SyncMap[K, V]) callSafely(fn func(key K, value V) bool, key K, value V) (rtrn bool) {
+	var p *panicPolicy[K, V]
+	if e := m.loadExtras(); e != nil {
+		p = e.panics.Load()
+	}
+	if p != nil && p.policy == PanicPropagate {
+		return fn(key, value)
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			rtrn = true
+			if p != nil && p.policy == PanicCallback && p.handler != nil {
+				p.handler(r, key, value)
+				return
+			}
+			logRangePanic(r, key)
+		}
+	}()
+	return fn(key, value)
+}
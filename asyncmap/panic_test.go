@@ -0,0 +1,76 @@
+package asyncmap
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPanicRecoverLogsAndContinues(t *testing.T) {
+	var logs bytes.Buffer
+	log.SetOutput(&logs)
+	defer log.SetOutput(os.Stderr)
+
+	m := NewSyncMap(map[string]int{"a": 1, "b": 2, "c": 3})
+	visited := 0
+	m.Range(func(key string, _ int) bool {
+		visited++
+		if key == "b" {
+			panic("boom")
+		}
+		return true
+	})
+	if visited != 3 {
+		t.Fatalf("Range visited %d entries after a recovered panic, want 3", visited)
+	}
+	if !strings.Contains(logs.String(), "boom") {
+		t.Fatalf("recovered panic not logged: %q", logs.String())
+	}
+}
+
+func TestPanicPropagateReleasesLock(t *testing.T) {
+	m := NewSyncMap(map[string]int{"a": 1})
+	m.SetPanicPolicy(PanicPropagate)
+	func() {
+		defer func() {
+			if r := recover(); r != "boom" {
+				t.Fatalf("recovered %v, want the callback's panic", r)
+			}
+		}()
+		m.Range(func(string, int) bool { panic("boom") })
+	}()
+	cleared := make(chan struct{})
+	go func() {
+		m.Clear()
+		close(cleared)
+	}()
+	select {
+	case <-cleared:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Clear blocked: the local lock was not released by the propagated panic")
+	}
+}
+
+func TestPanicCallbackReceivesEntry(t *testing.T) {
+	m := NewSyncMap(map[string]int{"a": 1, "b": 2})
+	var gotKey string
+	var gotValue int
+	var gotPanic any
+	m.SetPanicPolicy(PanicCallback, func(recovered any, key string, value int) {
+		gotPanic, gotKey, gotValue = recovered, key, value
+	})
+	visited := 0
+	m.Range(func(key string, _ int) bool {
+		visited++
+		if key == "b" {
+			panic("boom")
+		}
+		return true
+	})
+	if visited != 2 || gotPanic != "boom" || gotKey != "b" || gotValue != 2 {
+		t.Fatalf("handler got (%v, %q, %d) after %d visits, want (boom, b, 2) after 2", gotPanic, gotKey, gotValue, visited)
+	}
+}
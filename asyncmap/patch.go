@@ -0,0 +1,51 @@
+package asyncmap
+
+import "reflect"
+
+// Patch describes how to turn one map's contents into another's, as produced by ComputePatch.
+type Patch[K comparable, V any] struct {
+	// Set holds the entries that were added or changed.
+	Set map[K]V
+	// Removed holds the keys that were deleted.
+	Removed []K
+}
+
+// ComputePatch returns the patch that, applied to m with ApplyPatch, makes m hold the same
+// entries as target. Values are compared with reflect.DeepEqual. Each map is snapshotted
+// separately, so concurrent writes may make the patch reflect a mix of states.
+func (m *SyncMap[K, V]) ComputePatch(target *SyncMap[K, V]) Patch[K, V] {
+	current := m.ToMap()
+	patch := Patch[K, V]{Set: make(map[K]V)}
+	target.Range(func(key K, value V) bool {
+		if old, ok := current[key]; !ok || !reflect.DeepEqual(old, value) {
+			patch.Set[key] = value
+		}
+		delete(current, key)
+		return true
+	})
+	for key := range current {
+		patch.Removed = append(patch.Removed, key)
+	}
+	return patch
+}
+
+// ApplyPatch applies patch to the map under the local lock, storing every entry in patch.Set
+// and deleting every key in patch.Removed, and returns the number of changes applied.
+// Deleting a key that is already absent does not count as a change.
+func (m *SyncMap[K, V]) ApplyPatch(patch Patch[K, V]) int {
+	m.lazyInit()
+	m.mustBeWritable()
+	m.core.localLock.Lock()
+	defer m.core.localLock.Unlock()
+	changes := 0
+	for key, value := range patch.Set {
+		m.Store(key, value)
+		changes++
+	}
+	for _, key := range patch.Removed {
+		if _, ok := m.LoadAndDelete(key); ok {
+			changes++
+		}
+	}
+	return changes
+}
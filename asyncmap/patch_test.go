@@ -0,0 +1,24 @@
+package asyncmap
+
+import "testing"
+
+func TestPatchRoundTrip(t *testing.T) {
+	a := NewSyncMap(map[string][]int{"same": {1}, "changed": {2}, "gone": {3}})
+	b := NewSyncMap(map[string][]int{"same": {1}, "changed": {2, 2}, "new": {4}})
+	patch := a.ComputePatch(&b)
+	if len(patch.Set) != 2 || len(patch.Removed) != 1 || patch.Removed[0] != "gone" {
+		t.Fatalf("ComputePatch = %+v, want changed and new set, gone removed", patch)
+	}
+	if n := a.ApplyPatch(patch); n != 3 {
+		t.Fatalf("ApplyPatch applied %d changes, want 3", n)
+	}
+	if !a.Has("new") || a.Has("gone") || len(a.Get("changed")) != 2 || a.Len() != b.Len() {
+		t.Fatalf("after ApplyPatch a = %v, want %v", a.ToMap(), b.ToMap())
+	}
+	if again := a.ComputePatch(&b); len(again.Set) != 0 || len(again.Removed) != 0 {
+		t.Fatalf("patch after round trip = %+v, want empty", again)
+	}
+	if n := a.ApplyPatch(Patch[string, []int]{Removed: []string{"gone"}}); n != 0 {
+		t.Fatalf("removing an absent key counted %d changes, want 0", n)
+	}
+}
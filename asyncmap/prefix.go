@@ -0,0 +1,33 @@
+package asyncmap
+
+import (
+	"regexp"
+	"strings"
+)
+
+// RangePrefix calls fn for each entry of m whose key starts with prefix, following the
+// locking and panic handling of Range. If fn returns false, the iteration stops.
+// The underlying sync.Map has no key order, so this scans every entry and filters;
+// for large maps with frequent prefix queries, StringTrieMap avoids the full scan.
+func RangePrefix[V any](m *SyncMap[string, V], prefix string, fn func(key string, value V) bool) {
+	m.Range(func(key string, value V) bool {
+		if !strings.HasPrefix(key, prefix) {
+			return true
+		}
+		return fn(key, value)
+	})
+}
+
+// KeysMatching returns the keys of m that match pattern, for inspecting namespaced keys from
+// admin tooling. The pattern is precompiled so callers control the compilation cost; like
+// RangePrefix, it scans every key. The keys are returned in no particular order.
+func KeysMatching[V any](m *SyncMap[string, V], pattern *regexp.Regexp) []string {
+	var keys []string
+	m.Range(func(key string, _ V) bool {
+		if pattern.MatchString(key) {
+			keys = append(keys, key)
+		}
+		return true
+	})
+	return keys
+}
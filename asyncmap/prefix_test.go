@@ -0,0 +1,42 @@
+package asyncmap
+
+import (
+	"regexp"
+	"slices"
+	"testing"
+)
+
+func TestRangePrefix(t *testing.T) {
+	m := NewSyncMap(map[string]int{"user:1": 1, "user:2": 2, "users": 3, "admin:1": 4, "": 5})
+	var keys []string
+	RangePrefix(&m, "user:", func(key string, _ int) bool {
+		keys = append(keys, key)
+		return true
+	})
+	slices.Sort(keys)
+	if !slices.Equal(keys, []string{"user:1", "user:2"}) {
+		t.Fatalf("RangePrefix(user:) = %v", keys)
+	}
+	count := 0
+	RangePrefix(&m, "", func(string, int) bool {
+		count++
+		return count < 2
+	})
+	if count != 2 {
+		t.Fatalf("RangePrefix made %d calls after returning false, want 2", count)
+	}
+}
+
+func TestKeysMatching(t *testing.T) {
+	m := NewSyncMap(map[string]int{"user:1": 1, "user:22": 2, "admin:user:3": 3, "user:x": 4})
+	anchored := KeysMatching(&m, regexp.MustCompile(`^user:\d+$`))
+	slices.Sort(anchored)
+	if !slices.Equal(anchored, []string{"user:1", "user:22"}) {
+		t.Fatalf("anchored KeysMatching = %v", anchored)
+	}
+	unanchored := KeysMatching(&m, regexp.MustCompile(`user:\d`))
+	slices.Sort(unanchored)
+	if !slices.Equal(unanchored, []string{"admin:user:3", "user:1", "user:22"}) {
+		t.Fatalf("unanchored KeysMatching = %v", unanchored)
+	}
+}
@@ -0,0 +1,55 @@
+package asyncmap
+
+import (
+	"container/heap"
+	"slices"
+)
+
+// entryHeap is a min-heap of entries ordered by less on their values.
+type entryHeap[K comparable, V any] struct {
+	entries []Entry[K, V]
+	less    func(a, b V) bool
+}
+
+func (h *entryHeap[K, V]) Len() int           { return len(h.entries) }
+func (h *entryHeap[K, V]) Less(i, j int) bool { return h.less(h.entries[i].Value, h.entries[j].Value) }
+func (h *entryHeap[K, V]) Swap(i, j int)      { h.entries[i], h.entries[j] = h.entries[j], h.entries[i] }
+func (h *entryHeap[K, V]) Push(x any)         { h.entries = append(h.entries, x.(Entry[K, V])) }
+func (h *entryHeap[K, V]) Pop() any {
+	last := h.entries[len(h.entries)-1]
+	h.entries = h.entries[:len(h.entries)-1]
+	return last
+}
+
+// TopN returns the n entries with the greatest values according to less, greatest first.
+// It keeps a bounded heap of n entries while ranging, so it runs in O(m log n) for a map of
+// m entries instead of sorting everything. Fewer than n entries are returned if the map is smaller.
+// Ties are broken arbitrarily.
+func TopN[K comparable, V any](m SyncMap[K, V], n int, less func(a, b V) bool) []Entry[K, V] {
+	if n <= 0 {
+		return nil
+	}
+	h := &entryHeap[K, V]{less: less}
+	m.Range(func(key K, value V) bool {
+		if h.Len() < n {
+			heap.Push(h, Entry[K, V]{Key: key, Value: value})
+		} else if less(h.entries[0].Value, value) {
+			h.entries[0] = Entry[K, V]{Key: key, Value: value}
+			heap.Fix(h, 0)
+		}
+		return true
+	})
+	top := make([]Entry[K, V], 0, h.Len())
+	for h.Len() > 0 {
+		top = append(top, heap.Pop(h).(Entry[K, V]))
+	}
+	slices.Reverse(top)
+	return top
+}
+
+// BottomN returns the n entries with the smallest values according to less, smallest first.
+// It shares TopN's bounded heap by inverting the comparator, so it also runs in O(m log n).
+// Fewer than n entries are returned if the map is smaller. Ties are broken arbitrarily.
+func BottomN[K comparable, V any](m SyncMap[K, V], n int, less func(a, b V) bool) []Entry[K, V] {
+	return TopN(m, n, func(a, b V) bool { return less(b, a) })
+}
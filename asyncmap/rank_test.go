@@ -0,0 +1,52 @@
+package asyncmap
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+// rankedMap returns a map of 200 keys holding a shuffled permutation of 0..199, and the
+// values sorted ascending, so rankings can be checked against a full sort.
+func rankedMap() (SyncMap[int, int], []int) {
+	m := NewSyncMap[int, int]()
+	values := rand.New(rand.NewSource(7)).Perm(200)
+	for key, value := range values {
+		m.Store(key, value)
+	}
+	slices.Sort(values)
+	return m, values
+}
+
+func TestTopNMatchesFullSort(t *testing.T) {
+	m, sorted := rankedMap()
+	less := func(a, b int) bool { return a < b }
+	for _, n := range []int{0, 1, 10, 200, 500} {
+		var got []int
+		for _, entry := range TopN(m, n, less) {
+			if m.Get(entry.Key) != entry.Value {
+				t.Fatalf("TopN(%d) returned mismatched entry %v", n, entry)
+			}
+			got = append(got, entry.Value)
+		}
+		want := slices.Clone(sorted[len(sorted)-min(n, len(sorted)):])
+		slices.Reverse(want)
+		if !slices.Equal(got, want) {
+			t.Fatalf("TopN(%d) values = %v, want %v", n, got, want)
+		}
+	}
+}
+
+func TestBottomNMatchesFullSort(t *testing.T) {
+	m, sorted := rankedMap()
+	less := func(a, b int) bool { return a < b }
+	for _, n := range []int{0, 1, 10, 200, 500} {
+		var got []int
+		for _, entry := range BottomN(m, n, less) {
+			got = append(got, entry.Value)
+		}
+		if want := sorted[:min(n, len(sorted))]; !slices.Equal(got, want) {
+			t.Fatalf("BottomN(%d) values = %v, want %v", n, got, want)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package asyncmap
+
+// ReadOnly is the read-only subset of SyncMap's methods.
+// Passing a map as a ReadOnly lets an API boundary guarantee, at the type level,
+// that the callee cannot write to it. No copy is made; reads see live contents.
+type ReadOnly[K comparable, V any] interface {
+	Load(key K) (V, bool)
+	Get(key K) V
+	GetOrDefault(key K, defaultValue ...V) V
+	Has(key K) bool
+	Len() int
+	Range(fn func(key K, value V) bool)
+	Keys() []K
+	Values() []V
+	ToMap() map[K]V
+}
+
+// SyncMap must satisfy ReadOnly.
+var _ ReadOnly[string, any] = (*SyncMap[string, any])(nil)
+
+// AsReadOnly returns a ReadOnly view of the map. The view wraps the map rather than being
+// the map itself, so callers can't type-assert it back to *SyncMap to get write access.
+func (m *SyncMap[K, V]) AsReadOnly() ReadOnly[K, V] {
+	m.lazyInit()
+	return readOnlyView[K, V]{m: m}
+}
+
+// readOnlyView is the ReadOnly returned by AsReadOnly; it forwards reads to m.
+type readOnlyView[K comparable, V any] struct {
+	m *SyncMap[K, V]
+}
+
+func (v readOnlyView[K, V]) Load(key K) (V, bool) {
+	return v.m.Load(key)
+}
+
+func (v readOnlyView[K, V]) Get(key K) V {
+	return v.m.Get(key)
+}
+
+func (v readOnlyView[K, V]) GetOrDefault(key K, defaultValue ...V) V {
+	return v.m.GetOrDefault(key, defaultValue...)
+}
+
+func (v readOnlyView[K, V]) Has(key K) bool {
+	return v.m.Has(key)
+}
+
+func (v readOnlyView[K, V]) Len() int {
+	return v.m.Len()
+}
+
+func (v readOnlyView[K, V]) Range(fn func(key K, value V) bool) {
+	v.m.Range(fn)
+}
+
+func (v readOnlyView[K, V]) Keys() []K {
+	return v.m.Keys()
+}
+
+func (v readOnlyView[K, V]) Values() []V {
+	return v.m.Values()
+}
+
+func (v readOnlyView[K, V]) ToMap() map[K]V {
+	return v.m.ToMap()
+}
@@ -0,0 +1,39 @@
+package asyncmap
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+)
+
+func TestReadOnlyHasNoMutatingMethods(t *testing.T) {
+	allowed := []string{"Get", "GetOrDefault", "Has", "Keys", "Len", "Load", "Range", "ToMap", "Values"}
+	typ := reflect.TypeFor[ReadOnly[string, int]]()
+	var methods []string
+	for i := 0; i < typ.NumMethod(); i++ {
+		methods = append(methods, typ.Method(i).Name)
+	}
+	if !slices.Equal(methods, allowed) {
+		t.Fatalf("ReadOnly methods = %v, want exactly the read methods %v", methods, allowed)
+	}
+}
+
+func TestAsReadOnlyCannotBeAssertedBack(t *testing.T) {
+	m := NewSyncMap(map[string]int{"a": 1})
+	ro := m.AsReadOnly()
+	if _, ok := ro.(*SyncMap[string, int]); ok {
+		t.Fatal("the ReadOnly view can be type-asserted back to *SyncMap")
+	}
+	if _, ok := ro.(interface{ Store(string, int) }); ok {
+		t.Fatal("the ReadOnly view exposes Store")
+	}
+}
+
+func TestAsReadOnlySeesLiveContents(t *testing.T) {
+	m := NewSyncMap(map[string]int{"a": 1})
+	ro := m.AsReadOnly()
+	m.Store("b", 2)
+	if ro.Len() != 2 || ro.Get("b") != 2 || !ro.Has("a") {
+		t.Fatalf("ReadOnly view = %v, want the live contents", ro.ToMap())
+	}
+}
@@ -0,0 +1,82 @@
+package asyncmap
+
+import "iter"
+
+// Set is a concurrent set built on a SyncMap with empty struct values.
+// The zero value is an empty set ready to use.
+type Set[E comparable] struct {
+	m SyncMap[E, struct{}]
+}
+
+// StringSet is a Set of strings.
+type StringSet = Set[string]
+
+// NewSet creates a set holding elems.
+func NewSet[E comparable](elems ...E) *Set[E] {
+	s := &Set[E]{m: NewSyncMap[E, struct{}]()}
+	for _, elem := range elems {
+		s.Add(elem)
+	}
+	return s
+}
+
+// Add adds elem to the set.
+func (s *Set[E]) Add(elem E) {
+	s.m.Store(elem, struct{}{})
+}
+
+// Remove removes elem from the set.
+func (s *Set[E]) Remove(elem E) {
+	s.m.Delete(elem)
+}
+
+// Contains reports whether elem is in the set.
+func (s *Set[E]) Contains(elem E) bool {
+	return s.m.Has(elem)
+}
+
+// Len returns the number of elements in the set.
+func (s *Set[E]) Len() int {
+	return s.m.Len()
+}
+
+// All returns an iterator over the set's elements, in unspecified order.
+// Like SyncMap.Entries, it holds the map's local lock for the duration of the loop.
+func (s *Set[E]) All() iter.Seq[E] {
+	return func(yield func(E) bool) {
+		for entry := range s.m.Entries() {
+			if !yield(entry.Key) {
+				return
+			}
+		}
+	}
+}
+
+// Union returns a new set holding the elements in s, other, or both.
+func (s *Set[E]) Union(other *Set[E]) *Set[E] {
+	return &Set[E]{m: Merge(s.m, other.m)}
+}
+
+// Intersect returns a new set holding the elements in both s and other.
+func (s *Set[E]) Intersect(other *Set[E]) *Set[E] {
+	out := NewSet[E]()
+	s.m.Range(func(elem E, _ struct{}) bool {
+		if other.Contains(elem) {
+			out.Add(elem)
+		}
+		return true
+	})
+	return out
+}
+
+// Difference returns a new set holding the elements in s that are not in other.
+func (s *Set[E]) Difference(other *Set[E]) *Set[E] {
+	out := NewSet[E]()
+	s.m.Range(func(elem E, _ struct{}) bool {
+		if !other.Contains(elem) {
+			out.Add(elem)
+		}
+		return true
+	})
+	return out
+}
@@ -0,0 +1,48 @@
+package asyncmap
+
+import (
+	"slices"
+	"testing"
+)
+
+// sorted returns the elements of s in ascending order.
+func sorted(s *Set[int]) []int {
+	var elems []int
+	for elem := range s.All() {
+		elems = append(elems, elem)
+	}
+	slices.Sort(elems)
+	return elems
+}
+
+func TestSetMembership(t *testing.T) {
+	var s Set[int]
+	s.Add(1)
+	s.Add(1)
+	s.Add(2)
+	if !s.Contains(1) || s.Contains(3) || s.Len() != 2 {
+		t.Fatalf("set = %v, want [1 2]", sorted(&s))
+	}
+	s.Remove(1)
+	if s.Contains(1) || s.Len() != 1 {
+		t.Fatalf("after Remove set = %v, want [2]", sorted(&s))
+	}
+}
+
+func TestSetAlgebra(t *testing.T) {
+	a := NewSet(1, 2, 3, 4)
+	b := NewSet(3, 4, 5)
+	if got := sorted(a.Union(b)); !slices.Equal(got, []int{1, 2, 3, 4, 5}) {
+		t.Fatalf("Union = %v", got)
+	}
+	if got := sorted(a.Intersect(b)); !slices.Equal(got, []int{3, 4}) {
+		t.Fatalf("Intersect = %v", got)
+	}
+	if got := sorted(a.Difference(b)); !slices.Equal(got, []int{1, 2}) {
+		t.Fatalf("Difference = %v", got)
+	}
+	a.Union(b).Add(99)
+	if a.Contains(99) || b.Contains(99) {
+		t.Fatal("writing to a Union result changed an operand")
+	}
+}
@@ -0,0 +1,165 @@
+package asyncmap
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// sizeThreshold is the high-water mark registered by SetSizeThreshold.
+type sizeThreshold struct {
+	n  int
+	fn func(size int)
+	// above is true while the map is past n, so fn fires once per crossing.
+	above atomic.Bool
+}
+
+// SetSizeThreshold registers fn to be called when a write grows the map past n entries.
+// fn fires once when the size crosses above n and is re-armed only after the size drops back
+// to n or below, so it is not called on every write while the map stays large.
+// fn runs synchronously on the writing goroutine, possibly while the local lock is held,
+// so it must be quick and must not call methods that take the local lock (Range, Clear, ...).
+// Passing a nil fn removes the threshold.
+func (m *SyncMap[K, V]) SetSizeThreshold(n int, fn func(size int)) {
+	m.lazyInit()
+	if fn == nil {
+		m.initExtras().threshold.Store(nil)
+		return
+	}
+	t := &sizeThreshold{n: n, fn: fn}
+	t.above.Store(m.count() > n)
+	m.initExtras().threshold.Store(t)
+}
+
+// rearmThreshold re-arms the size threshold after the contents are replaced wholesale.
+func (m *SyncMap[K, V]) rearmThreshold() {
+	if e := m.loadExtras(); e != nil {
+		if threshold := e.threshold.Load(); threshold != nil {
+			threshold.above.Store(false)
+		}
+	}
+}
+
+// resize adjusts t's size counter by delta after a key is added to or removed from t,
+// and fires the size threshold callback when the map crosses it.
+func (m *SyncMap[K, V]) resize(t *table, delta int64) {
+	t.size.Add(delta)
+	e := m.loadExtras()
+	if e == nil {
+		return
+	}
+	threshold := e.threshold.Load()
+	if threshold == nil {
+		return
+	}
+	size := m.count()
+	if size <= threshold.n {
+		threshold.above.Store(false)
+		return
+	}
+	if threshold.above.CompareAndSwap(false, true) {
+		threshold.fn(size)
+	}
+}
+
+// count returns the number of entries visible in the map. It reads the size counter,
+// except for forked maps, whose visible entries include the parent's and must be walked.
+func (m *SyncMap[K, V]) count() int {
+	if m.parent == nil {
+		return int(m.table().size.Load())
+	}
+	n := 0
+	m.rangeRaw(func(_, _ any) bool {
+		n++
+		return true
+	})
+	return n
+}
+
+// WaitEmpty blocks until the map has no entries or ctx is done, returning ctx.Err() in the
+// latter case. It lets a coordinator wait for all queued work items to be consumed before
+// shutting down. Waiters are woken by the delete that empties the map, so no polling is involved.
+func (m *SyncMap[K, V]) WaitEmpty(ctx context.Context) error {
+	m.lazyInit()
+	for {
+		emptied := m.emptiedChan()
+		if m.count() == 0 {
+			return nil
+		}
+		select {
+		case <-emptied:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// emptiedChan returns the channel that is closed the next time the map becomes empty.
+func (m *SyncMap[K, V]) emptiedChan() chan struct{} {
+	e := m.initExtras()
+	for {
+		if current := e.emptied.Load(); current != nil {
+			return *current
+		}
+		ch := make(chan struct{})
+		if e.emptied.CompareAndSwap(nil, &ch) {
+			return ch
+		}
+	}
+}
+
+// notifyIfEmpty wakes WaitEmpty callers if the map is now empty.
+// It is called after every delete and whenever the contents are replaced wholesale.
+func (m *SyncMap[K, V]) notifyIfEmpty() {
+	e := m.loadExtras()
+	if e == nil {
+		return
+	}
+	current := e.emptied.Load()
+	if current == nil || m.count() != 0 {
+		return
+	}
+	if e.emptied.CompareAndSwap(current, nil) {
+		close(*current)
+	}
+}
+
+// SetMaxSize sets the maximum number of entries TryStore allows. A limit of 0 or less
+// removes it. The limit only applies to TryStore; Store and other writes are never refused.
+func (m *SyncMap[K, V]) SetMaxSize(n int) {
+	m.lazyInit()
+	m.initExtras().maxSize.Store(int64(max(n, 0)))
+}
+
+// TryStore stores value under key unless that would add a new key to a map already holding
+// the maximum number of entries set by SetMaxSize, in which case it returns ErrFull and
+// stores nothing. Overwriting an existing key is always allowed. This gives callers
+// backpressure without evicting anything. The check and store hold the local lock.
+func (m *SyncMap[K, V]) TryStore(key K, value V) error {
+	m.lazyInit()
+	m.mustBeWritable()
+	m.core.localLock.Lock()
+	defer m.core.localLock.Unlock()
+	if e := m.loadExtras(); e != nil && e.maxSize.Load() > 0 && !m.Has(key) && int64(m.count()) >= e.maxSize.Load() {
+		return ErrFull
+	}
+	m.Store(key, value)
+	return nil
+}
+
+// entryOverheadBytes is a rough estimate of what the underlying sync.Map spends per entry
+// beyond the key and value themselves: the boxed key and value interfaces, the entry
+// pointer and its share of the internal hash table.
+const entryOverheadBytes = 64
+
+// ApproxSizeBytes estimates the map's memory footprint as the sum of sizeOf over every entry
+// plus a fixed per-entry overhead for the underlying storage. The caller supplies sizeOf
+// because the size of an arbitrary V isn't knowable generically. The result is a rough
+// figure for capacity planning, not an exact measurement.
+func (m *SyncMap[K, V]) ApproxSizeBytes(sizeOf func(key K, value V) int) int64 {
+	var total int64
+	m.Range(func(key K, value V) bool {
+		total += int64(sizeOf(key, value)) + entryOverheadBytes
+		return true
+	})
+	return total
+}
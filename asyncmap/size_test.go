@@ -0,0 +1,140 @@
+package asyncmap
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSizeThresholdFiresOncePerCrossing(t *testing.T) {
+	m := NewSyncMap[int, int]()
+	var fired []int
+	m.SetSizeThreshold(2, func(size int) { fired = append(fired, size) })
+	m.Store(1, 1)
+	m.Store(2, 2)
+	if len(fired) != 0 {
+		t.Fatalf("threshold fired at size %v, not past 2", fired)
+	}
+	m.Store(3, 3)
+	m.Store(4, 4)
+	if !slices.Equal(fired, []int{3}) {
+		t.Fatalf("fired = %v, want [3]", fired)
+	}
+	m.Delete(4)
+	m.Store(5, 5)
+	if !slices.Equal(fired, []int{3}) {
+		t.Fatalf("fired again without dropping back to 2: %v", fired)
+	}
+	m.Delete(5)
+	m.Delete(3)
+	m.Store(6, 6)
+	if !slices.Equal(fired, []int{3, 3}) {
+		t.Fatalf("fired = %v after crossing down and up again, want [3 3]", fired)
+	}
+}
+
+func TestLenUnderMixedOperations(t *testing.T) {
+	m := NewSyncMap[int, int]()
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 300; i++ {
+				key := (g*7 + i) % 64
+				switch i % 5 {
+				case 0:
+					m.Store(key, i)
+				case 1:
+					m.LoadOrStore(key, i)
+				case 2:
+					m.Delete(key)
+				case 3:
+					m.Swap(key, i)
+				case 4:
+					m.LoadAndDelete(key)
+				}
+				if n := m.Len(); n < 0 || n > 64 {
+					t.Errorf("Len = %d mid-run, want 0..64", n)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if got, want := m.Len(), len(m.ToMap()); got != want {
+		t.Fatalf("Len = %d after mixed operations, want %d", got, want)
+	}
+}
+
+func TestWaitEmptyConcurrentDrain(t *testing.T) {
+	m := NewSyncMap[int, int]()
+	for i := 0; i < 100; i++ {
+		m.Store(i, i)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	waited := make(chan error, 3)
+	for w := 0; w < 3; w++ {
+		go func() { waited <- m.WaitEmpty(ctx) }()
+	}
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := g; i < 100; i += 4 {
+				m.Delete(i)
+			}
+		}()
+	}
+	wg.Wait()
+	for w := 0; w < 3; w++ {
+		if err := <-waited; err != nil {
+			t.Fatalf("WaitEmpty = %v after the map was drained", err)
+		}
+	}
+}
+
+func TestWaitEmptyContextDone(t *testing.T) {
+	m := NewSyncMap(map[int]int{1: 1})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := m.WaitEmpty(ctx); err != context.Canceled {
+		t.Fatalf("WaitEmpty = %v on a cancelled context, want context.Canceled", err)
+	}
+}
+
+func TestTryStoreLimit(t *testing.T) {
+	m := NewSyncMap(map[string]int{"a": 1})
+	m.SetMaxSize(2)
+	if err := m.TryStore("b", 2); err != nil {
+		t.Fatalf("TryStore under the limit = %v", err)
+	}
+	if err := m.TryStore("c", 3); !errors.Is(err, ErrFull) || m.Has("c") {
+		t.Fatalf("TryStore at the limit = %v (Has(c) = %v), want ErrFull and nothing stored", err, m.Has("c"))
+	}
+	if err := m.TryStore("a", 10); err != nil || m.Get("a") != 10 {
+		t.Fatalf("overwriting at the limit = %v, want it allowed", err)
+	}
+	m.SetMaxSize(0)
+	if err := m.TryStore("c", 3); err != nil {
+		t.Fatalf("TryStore with the limit removed = %v", err)
+	}
+}
+
+func TestApproxSizeBytes(t *testing.T) {
+	m := NewSyncMap(map[string]string{"a": "xyz", "bb": "", "ccc": "12345"})
+	sizeOf := func(key, value string) int { return len(key) + len(value) }
+	// (1+3) + (2+0) + (3+5) bytes of payload plus the fixed overhead for each of 3 entries.
+	if got, want := m.ApproxSizeBytes(sizeOf), int64(14+3*entryOverheadBytes); got != want {
+		t.Fatalf("ApproxSizeBytes = %d, want %d", got, want)
+	}
+	empty := NewSyncMap[string, string]()
+	if got := empty.ApproxSizeBytes(sizeOf); got != 0 {
+		t.Fatalf("ApproxSizeBytes of an empty map = %d, want 0", got)
+	}
+}
@@ -0,0 +1,266 @@
+package asyncmap
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// The primitives in this file are the only code that touches a map's table directly.
+// They hide the tombstones and read-through of forked maps, and run the bookkeeping
+// (size counter, versions, tees) that every write needs.
+
+// table holds a map's contents. A SyncMap reaches its table through an atomic pointer,
+// so operations such as SwapContents can replace the whole contents in one step.
+type table struct {
+	entries *sync.Map
+	// size counts the entries, excluding tombstones.
+	size atomic.Int64
+	// meta holds the metadata attached with StoreWithMeta, keyed like entries.
+	meta sync.Map
+	// throttled holds the time of the last StoreThrottled write per key, keyed like entries.
+	throttled sync.Map
+	// own backs entries unless the table wraps a caller's sync.Map.
+	own sync.Map
+}
+
+// newTable returns an empty table.
+func newTable() *table {
+	t := &table{}
+	t.entries = &t.own
+	return t
+}
+
+// table returns the map's current table. Each primitive loads it once, so a single
+// operation never straddles two tables.
+func (m *SyncMap[K, V]) table() *table {
+	return m.core.contents.Load()
+}
+
+// load returns the raw value visible for key, reading through to the parent of a forked map.
+func (m *SyncMap[K, V]) load(key K) (any, bool) {
+	value, ok := m.table().entries.Load(key)
+	if ok {
+		if isTombstone(value) {
+			return nil, false
+		}
+		return value, true
+	}
+	if m.parent == nil {
+		return nil, false
+	}
+	return m.parent.load(key)
+}
+
+// rangeRaw calls fn for every raw entry visible in the map, including entries read through
+// from the parent of a forked map. It does not take the local lock.
+func (m *SyncMap[K, V]) rangeRaw(fn func(key, value any) bool) {
+	t := m.table()
+	stopped := false
+	t.entries.Range(func(key, value any) bool {
+		if isTombstone(value) {
+			return true
+		}
+		if !fn(key, value) {
+			stopped = true
+			return false
+		}
+		return true
+	})
+	if stopped || m.parent == nil {
+		return
+	}
+	m.parent.rangeRaw(func(key, value any) bool {
+		if _, owned := t.entries.Load(key); owned {
+			return true
+		}
+		return fn(key, value)
+	})
+}
+
+// loadOrStore is the fork-aware form of sync.Map.LoadOrStore.
+func (m *SyncMap[K, V]) loadOrStore(key K, value V) (any, bool) {
+	t := m.table()
+	if m.parent == nil {
+		actual, loaded := t.entries.LoadOrStore(key, value)
+		if !loaded {
+			m.afterStore(t, key, value, true)
+		}
+		return actual, loaded
+	}
+	for {
+		current, ok := t.entries.Load(key)
+		switch {
+		case !ok:
+			if inherited, found := m.parent.load(key); found {
+				return inherited, true
+			}
+			actual, loaded := t.entries.LoadOrStore(key, value)
+			if !loaded {
+				m.afterStore(t, key, value, true)
+				return actual, false
+			}
+			if !isTombstone(actual) {
+				return actual, true
+			}
+		case isTombstone(current):
+			if t.entries.CompareAndSwap(key, tombstone{}, value) {
+				m.afterStore(t, key, value, true)
+				return value, false
+			}
+		default:
+			return current, true
+		}
+	}
+}
+
+// store writes value under key, overwriting any tombstone.
+func (m *SyncMap[K, V]) store(key K, value V) (previous any, loaded bool) {
+	t := m.table()
+	previous, loaded = t.entries.Swap(key, value)
+	m.afterStore(t, key, value, !loaded || isTombstone(previous))
+	return previous, loaded
+}
+
+// storeBatch stores every entry, adjusting the size counter once for the whole batch
+// rather than per entry.
+func (m *SyncMap[K, V]) storeBatch(entries map[K]V) {
+	t := m.table()
+	var added int64
+	for key, value := range entries {
+		previous, loaded := t.entries.Swap(key, value)
+		if !loaded || isTombstone(previous) {
+			added++
+		}
+		// The size counter is adjusted below, so report no growth here.
+		m.afterStore(t, key, value, false)
+	}
+	if added != 0 {
+		m.resize(t, added)
+	}
+}
+
+// swap is the fork-aware form of sync.Map.Swap.
+func (m *SyncMap[K, V]) swap(key K, value V) (any, bool) {
+	previous, loaded := m.store(key, value)
+	return m.resolvePrevious(key, previous, loaded)
+}
+
+// loadAndDelete is the fork-aware form of sync.Map.LoadAndDelete.
+// In a forked map the key is replaced by a tombstone so the parent's entry stays hidden.
+func (m *SyncMap[K, V]) loadAndDelete(key K) (any, bool) {
+	t := m.table()
+	if m.parent == nil {
+		previous, loaded := t.entries.LoadAndDelete(key)
+		if loaded {
+			m.afterDelete(t, key, true)
+		}
+		return previous, loaded
+	}
+	previous, loaded := t.entries.Swap(key, tombstone{})
+	m.afterDelete(t, key, loaded && !isTombstone(previous))
+	return m.resolvePrevious(key, previous, loaded)
+}
+
+// throttleTimes returns the StoreThrottled timestamps kept in the map's current table.
+func (m *SyncMap[K, V]) throttleTimes() *sync.Map {
+	return &m.table().throttled
+}
+
+// storeMeta attaches meta to key in the map's current table.
+func (m *SyncMap[K, V]) storeMeta(key K, meta any) {
+	m.table().meta.Store(key, meta)
+}
+
+// loadMeta returns the metadata attached to key, reading through to the parent of a forked
+// map for keys the child has never written or deleted.
+func (m *SyncMap[K, V]) loadMeta(key K) (any, bool) {
+	t := m.table()
+	if _, owned := t.entries.Load(key); owned || m.parent == nil {
+		return t.meta.Load(key)
+	}
+	return m.parent.loadMeta(key)
+}
+
+// afterStore is called by the write primitives above after value was stored under key in t.
+// added reports whether the key was new to t.
+func (m *SyncMap[K, V]) afterStore(t *table, key K, value V, added bool) {
+	if added {
+		m.resize(t, 1)
+	}
+	e := m.loadExtras()
+	if e == nil {
+		return
+	}
+	if versions := e.versions.Load(); versions != nil {
+		versions.bump(key)
+	}
+	if tees := e.tees.Load(); tees != nil {
+		for _, replica := range *tees {
+			replica.Store(key, value)
+		}
+	}
+}
+
+// afterDelete is called by the write primitives above after key was deleted from t.
+// removed reports whether an entry was removed from t.
+func (m *SyncMap[K, V]) afterDelete(t *table, key K, removed bool) {
+	if removed {
+		m.resize(t, -1)
+	}
+	t.meta.Delete(key)
+	t.throttled.Delete(key)
+	e := m.loadExtras()
+	if e == nil {
+		return
+	}
+	if versions := e.versions.Load(); versions != nil {
+		versions.forget(key)
+	}
+	if tees := e.tees.Load(); tees != nil {
+		for _, replica := range *tees {
+			replica.Delete(key)
+		}
+	}
+	m.notifyIfEmpty()
+}
+
+// resolvePrevious turns the result of a Swap on the child's own storage into the value
+// that was visible before the Swap, which may have come from the parent.
+func (m *SyncMap[K, V]) resolvePrevious(key K, previous any, loaded bool) (any, bool) {
+	if loaded {
+		if isTombstone(previous) {
+			return nil, false
+		}
+		return previous, true
+	}
+	if m.parent == nil {
+		return nil, false
+	}
+	return m.parent.load(key)
+}
+
+// deleteForeign removes an entry whose key is not a K, which can only have been written
+// through the raw sync.Map of a wrapped map. Such entries are never counted in the size
+// counter, so no bookkeeping runs.
+func (m *SyncMap[K, V]) deleteForeign(key any) {
+	m.table().entries.Delete(key)
+}
+
+// adoptEntries replaces the map's table with one backed by raw, so both share storage.
+// Every entry with a K key already in raw is counted, matching what the typed delete
+// path will later subtract.
+func (m *SyncMap[K, V]) adoptEntries(raw *sync.Map) {
+	t := &table{entries: raw}
+	raw.Range(func(key, _ any) bool {
+		if _, ok := key.(K); ok {
+			t.size.Add(1)
+		}
+		return true
+	})
+	m.core.contents.Store(t)
+}
+
+// rawEntries returns the sync.Map backing the map's current table.
+func (m *SyncMap[K, V]) rawEntries() *sync.Map {
+	return m.table().entries
+}
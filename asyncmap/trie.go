@@ -0,0 +1,168 @@
+package asyncmap
+
+import (
+	"slices"
+	"sync"
+)
+
+// StringTrieMap is a concurrent map keyed by strings and backed by a byte-wise trie, so
+// prefix queries (PrefixKeys, RangePrefix, LongestPrefix) only visit the matching subtree
+// instead of scanning every entry. It suits routing tables and autocomplete.
+// Reads share a read lock and writes take the write lock; iteration is in key order.
+type StringTrieMap[V any] struct {
+	mu   sync.RWMutex
+	root trieNode[V]
+	size int
+}
+
+// trieNode is one byte position in a StringTrieMap. set distinguishes a stored zero value
+// from an interior node with no entry of its own.
+type trieNode[V any] struct {
+	children map[byte]*trieNode[V]
+	value    V
+	set      bool
+}
+
+// NewStringTrieMap creates an empty StringTrieMap.
+func NewStringTrieMap[V any]() *StringTrieMap[V] {
+	return &StringTrieMap[V]{}
+}
+
+// find returns the node for key, or nil if the path for key does not exist.
+func (t *StringTrieMap[V]) find(key string) *trieNode[V] {
+	node := &t.root
+	for i := 0; i < len(key) && node != nil; i++ {
+		node = node.children[key[i]]
+	}
+	return node
+}
+
+// Load returns the value stored for key and whether it was present.
+func (t *StringTrieMap[V]) Load(key string) (V, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	if node := t.find(key); node != nil && node.set {
+		return node.value, true
+	}
+	var zero V
+	return zero, false
+}
+
+// Store sets the value for key.
+func (t *StringTrieMap[V]) Store(key string, value V) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	node := &t.root
+	for i := 0; i < len(key); i++ {
+		child := node.children[key[i]]
+		if child == nil {
+			if node.children == nil {
+				node.children = make(map[byte]*trieNode[V])
+			}
+			child = &trieNode[V]{}
+			node.children[key[i]] = child
+		}
+		node = child
+	}
+	if !node.set {
+		t.size++
+	}
+	node.value, node.set = value, true
+}
+
+// Delete removes the entry for key, pruning any nodes left without entries or children.
+func (t *StringTrieMap[V]) Delete(key string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	path := make([]*trieNode[V], 0, len(key)+1)
+	node := &t.root
+	path = append(path, node)
+	for i := 0; i < len(key); i++ {
+		if node = node.children[key[i]]; node == nil {
+			return
+		}
+		path = append(path, node)
+	}
+	if !node.set {
+		return
+	}
+	var zero V
+	node.value, node.set = zero, false
+	t.size--
+	for i := len(key); i > 0 && !path[i].set && len(path[i].children) == 0; i-- {
+		delete(path[i-1].children, key[i-1])
+	}
+}
+
+// Len returns the number of entries in the map.
+func (t *StringTrieMap[V]) Len() int {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.size
+}
+
+// Range calls fn for each entry in key order. If fn returns false, the iteration stops.
+// The read lock is held throughout, so fn must not write to the map.
+func (t *StringTrieMap[V]) Range(fn func(key string, value V) bool) {
+	t.RangePrefix("", fn)
+}
+
+// RangePrefix calls fn, in key order, for each entry whose key starts with prefix.
+// Only the subtree under prefix is visited. If fn returns false, the iteration stops.
+// The read lock is held throughout, so fn must not write to the map.
+func (t *StringTrieMap[V]) RangePrefix(prefix string, fn func(key string, value V) bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	if node := t.find(prefix); node != nil {
+		node.walk([]byte(prefix), fn)
+	}
+}
+
+// walk visits node and its descendants in key order, with key holding the bytes leading to node.
+// It returns false once fn has asked to stop.
+func (node *trieNode[V]) walk(key []byte, fn func(key string, value V) bool) bool {
+	if node.set && !fn(string(key), node.value) {
+		return false
+	}
+	edges := make([]byte, 0, len(node.children))
+	for b := range node.children {
+		edges = append(edges, b)
+	}
+	slices.Sort(edges)
+	for _, b := range edges {
+		if !node.children[b].walk(append(key, b), fn) {
+			return false
+		}
+	}
+	return true
+}
+
+// PrefixKeys returns the keys starting with prefix, in key order.
+func (t *StringTrieMap[V]) PrefixKeys(prefix string) []string {
+	var keys []string
+	t.RangePrefix(prefix, func(key string, _ V) bool {
+		keys = append(keys, key)
+		return true
+	})
+	return keys
+}
+
+// LongestPrefix returns the longest stored key that is a prefix of s, with its value,
+// as a routing table would when matching a path. ok is false if no stored key is a prefix of s.
+func (t *StringTrieMap[V]) LongestPrefix(s string) (key string, value V, ok bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	node := &t.root
+	for i := 0; ; i++ {
+		if node.set {
+			key, value, ok = s[:i], node.value, true
+		}
+		if i == len(s) {
+			break
+		}
+		if node = node.children[s[i]]; node == nil {
+			break
+		}
+	}
+	return key, value, ok
+}
@@ -0,0 +1,81 @@
+package asyncmap
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+)
+
+func TestStringTriePrefixKeys(t *testing.T) {
+	trie := NewStringTrieMap[int]()
+	for i, key := range []string{"b", "ab", "a", "abc", "abd", "ac"} {
+		trie.Store(key, i)
+	}
+	if got := trie.PrefixKeys("ab"); !slices.Equal(got, []string{"ab", "abc", "abd"}) {
+		t.Fatalf("PrefixKeys(ab) = %v", got)
+	}
+	if got := trie.PrefixKeys(""); !slices.Equal(got, []string{"a", "ab", "abc", "abd", "ac", "b"}) {
+		t.Fatalf("PrefixKeys() = %v, want every key in order", got)
+	}
+	if got := trie.PrefixKeys("x"); len(got) != 0 {
+		t.Fatalf("PrefixKeys(x) = %v, want none", got)
+	}
+	trie.Delete("abc")
+	if got := trie.PrefixKeys("ab"); !slices.Equal(got, []string{"ab", "abd"}) || trie.Len() != 5 {
+		t.Fatalf("after Delete PrefixKeys(ab) = %v with Len %d", got, trie.Len())
+	}
+}
+
+func TestStringTrieLongestPrefix(t *testing.T) {
+	trie := NewStringTrieMap[string]()
+	trie.Store("/", "root")
+	trie.Store("/api", "api")
+	trie.Store("/api/v2", "v2")
+	cases := []struct{ path, key, value string }{
+		{"/api/v2/users", "/api/v2", "v2"},
+		{"/api/v1", "/api", "api"},
+		{"/static", "/", "root"},
+		{"/api", "/api", "api"},
+	}
+	for _, c := range cases {
+		if key, value, ok := trie.LongestPrefix(c.path); !ok || key != c.key || value != c.value {
+			t.Errorf("LongestPrefix(%q) = (%q, %q, %v), want (%q, %q, true)", c.path, key, value, ok, c.key, c.value)
+		}
+	}
+	if _, _, ok := trie.LongestPrefix("api"); ok {
+		t.Error("LongestPrefix matched a string no key is a prefix of")
+	}
+}
+
+// prefixWorkload is the key set for the prefix scan benchmarks: 100 namespaces of 100 keys.
+func prefixWorkload() []string {
+	keys := make([]string, 0, 10000)
+	for ns := 0; ns < 100; ns++ {
+		for i := 0; i < 100; i++ {
+			keys = append(keys, fmt.Sprintf("ns%02d:key%03d", ns, i))
+		}
+	}
+	return keys
+}
+
+func BenchmarkStringTrieRangePrefix(b *testing.B) {
+	trie := NewStringTrieMap[int]()
+	for i, key := range prefixWorkload() {
+		trie.Store(key, i)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		trie.RangePrefix("ns42:", func(string, int) bool { return true })
+	}
+}
+
+func BenchmarkSyncMapRangePrefix(b *testing.B) {
+	m := NewSyncMap[string, int]()
+	for i, key := range prefixWorkload() {
+		m.Store(key, i)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		RangePrefix(&m, "ns42:", func(string, int) bool { return true })
+	}
+}
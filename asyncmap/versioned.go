@@ -0,0 +1,112 @@
+package asyncmap
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// Versions are kept in a side table keyed like the map, rather than by wrapping the stored
+// values, so that typed reads, Range and the raw sync.Map keep seeing plain V values.
+// Version tracking starts the first time LoadVersioned or StoreVersioned is called; from then
+// on every write to a key, versioned or not, bumps that key's version. Versions are drawn from
+// a per-map clock, so no two writes ever share one and a re-created key never reuses an old
+// version. Deleting a key drops its counter, so the table holds at most one counter per
+// present key; every absent key shares the table's floor version, which each delete raises so
+// that tokens taken before it can't be replayed. Replacing the contents wholesale (Reset,
+// Rotate, SwapContents) starts a new generation above every version handed out so far, which
+// invalidates all outstanding tokens.
+
+// versionTable is one generation of per-key versions.
+type versionTable struct {
+	// counters maps each key written in this generation, and not deleted since, to its
+	// *atomic.Uint64 version.
+	counters sync.Map
+	// floor is the version of every key without a counter.
+	floor atomic.Uint64
+	// clock hands out versions; it is shared by all generations of a map.
+	clock *atomic.Uint64
+}
+
+// LoadVersioned returns the value for key, the key's current version, and whether the key
+// was present under the same rules as Load. The version is an opaque token to pass to
+// StoreVersioned; an absent key also has a version, so it can be created optimistically.
+// That version is shared by all absent keys and changes on every delete, so creating a key
+// this way fails if any key was deleted after LoadVersioned.
+func (m *SyncMap[K, V]) LoadVersioned(key K) (value V, version uint64, ok bool) {
+	m.lazyInit()
+	for {
+		before := m.versionTable().load(key)
+		value, ok = m.lookup(key)
+		if after := m.versionTable().load(key); after == before {
+			return value, before, ok
+		}
+	}
+}
+
+// StoreVersioned stores value under key only if the key's version still equals expected,
+// as returned by LoadVersioned, and reports whether it did. A successful store bumps the
+// version, so of several updaters holding the same token exactly one wins.
+// The check and store hold the local lock. Plain writes (Store, Delete, ...) bump versions
+// too but are not serialized with StoreVersioned, so keys that need optimistic concurrency
+// should be written through StoreVersioned only.
+func (m *SyncMap[K, V]) StoreVersioned(key K, value V, expected uint64) bool {
+	m.lazyInit()
+	m.mustBeWritable()
+	m.core.localLock.Lock()
+	defer m.core.localLock.Unlock()
+	if m.versionTable().load(key) != expected {
+		return false
+	}
+	m.store(key, value)
+	return true
+}
+
+// versionTable returns the map's current version table, creating it on first use.
+func (m *SyncMap[K, V]) versionTable() *versionTable {
+	e := m.initExtras()
+	if versions := e.versions.Load(); versions != nil {
+		return versions
+	}
+	e.versions.CompareAndSwap(nil, &versionTable{clock: &atomic.Uint64{}})
+	return e.versions.Load()
+}
+
+// newVersionGeneration replaces the map's version table, if version tracking is on, with an
+// empty one whose floor is above every version handed out so far. It is called whenever the
+// contents are replaced wholesale, with the local lock held.
+func (m *SyncMap[K, V]) newVersionGeneration() {
+	e := m.loadExtras()
+	if e == nil {
+		return
+	}
+	if current := e.versions.Load(); current != nil {
+		next := &versionTable{clock: current.clock}
+		next.floor.Store(current.clock.Add(1))
+		e.versions.Store(next)
+	}
+}
+
+// load returns the current version of key.
+func (v *versionTable) load(key any) uint64 {
+	counter, ok := v.counters.Load(key)
+	if !ok {
+		return v.floor.Load()
+	}
+	return counter.(*atomic.Uint64).Load()
+}
+
+// bump gives key a new version.
+func (v *versionTable) bump(key any) {
+	counter, ok := v.counters.Load(key)
+	if !ok {
+		counter, _ = v.counters.LoadOrStore(key, &atomic.Uint64{})
+	}
+	counter.(*atomic.Uint64).Store(v.clock.Add(1))
+}
+
+// forget drops key's counter after it was deleted, first raising the floor above every
+// version handed out so far so that key's old versions are never seen again.
+func (v *versionTable) forget(key any) {
+	v.floor.Store(v.clock.Add(1))
+	v.counters.Delete(key)
+}
@@ -0,0 +1,99 @@
+package asyncmap
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestVersionsSurviveWholesaleReplacement(t *testing.T) {
+	m := NewSyncMap[string, int]()
+	m.Store("a", 1)
+	_, stale, _ := m.LoadVersioned("a")
+	m.Reset()
+	if m.StoreVersioned("a", 99, stale) {
+		t.Fatal("StoreVersioned with a token from before Reset succeeded")
+	}
+
+	_, stale, _ = m.LoadVersioned("b")
+	m.Rotate()
+	if m.StoreVersioned("b", 1, stale) {
+		t.Fatal("StoreVersioned with a token from before Rotate succeeded")
+	}
+
+	other := NewSyncMap(map[string]int{"c": 3})
+	_, stale, _ = m.LoadVersioned("c")
+	SwapContents(&m, &other)
+	if m.StoreVersioned("c", 4, stale) {
+		t.Fatal("StoreVersioned with a token from before SwapContents succeeded")
+	}
+
+	_, fresh, _ := m.LoadVersioned("c")
+	if !m.StoreVersioned("c", 4, fresh) {
+		t.Fatal("StoreVersioned with a fresh token failed")
+	}
+}
+
+func TestStoreVersionedRacingUpdaters(t *testing.T) {
+	m := NewSyncMap[string, int]()
+	_, token, _ := m.LoadVersioned("n")
+	var wins atomic.Int32
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if m.StoreVersioned("n", g, token) {
+				wins.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+	if wins.Load() != 1 {
+		t.Fatalf("%d updaters won with the same token, want 1", wins.Load())
+	}
+
+	m.Store("n", 0)
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				for {
+					value, version, _ := m.LoadVersioned("n")
+					if m.StoreVersioned("n", value+1, version) {
+						break
+					}
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if got := m.Get("n"); got != 800 {
+		t.Fatalf("n = %d after optimistic increments, want 800", got)
+	}
+}
+
+func TestVersionCountersPrunedOnDelete(t *testing.T) {
+	m := NewSyncMap[int, int]()
+	_, stale, _ := m.LoadVersioned(0)
+	for i := 0; i < 1000; i++ {
+		m.Store(i, i)
+		m.Delete(i)
+	}
+	counters := 0
+	m.versionTable().counters.Range(func(_, _ any) bool {
+		counters++
+		return true
+	})
+	if counters != 0 {
+		t.Fatalf("%d version counters left after deleting every key, want 0", counters)
+	}
+	if m.StoreVersioned(0, 1, stale) {
+		t.Fatal("a token for an absent key stayed valid across a store and delete of that key")
+	}
+	_, fresh, _ := m.LoadVersioned(0)
+	if !m.StoreVersioned(0, 1, fresh) {
+		t.Fatal("StoreVersioned with a fresh token for an absent key failed")
+	}
+}
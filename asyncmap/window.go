@@ -0,0 +1,124 @@
+package asyncmap
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// WindowCounter counts events per key over a sliding time window, for per-key rate limiting
+// and hot-key detection. The window is split into buckets; counts older than the window fall
+// out one bucket at a time, so Count is accurate to within one bucket width.
+// Keys with no events left in the window are evicted, at most once per window, by Incr.
+type WindowCounter[K comparable] struct {
+	rings   SyncMap[K, *windowRing]
+	width   time.Duration
+	buckets int
+	// swept is the epoch of the last eviction sweep.
+	swept atomic.Int64
+}
+
+// windowRing is the ring buffer of bucketed counts for one key.
+type windowRing struct {
+	mu     sync.Mutex
+	counts []int
+	// head is the index in counts of the bucket for epoch.
+	head  int
+	epoch int64
+	// evicted is set when the ring is removed from the counter; it must not be counted into.
+	evicted bool
+}
+
+// NewWindowCounter creates a WindowCounter covering window, split into the given number of
+// buckets. More buckets make the window slide more smoothly at the cost of memory per key.
+// buckets below 1 is treated as 1.
+func NewWindowCounter[K comparable](window time.Duration, buckets int) *WindowCounter[K] {
+	buckets = max(buckets, 1)
+	c := &WindowCounter[K]{
+		rings:   NewSyncMap[K, *windowRing](),
+		width:   max(window/time.Duration(buckets), 1),
+		buckets: buckets,
+	}
+	c.swept.Store(c.epoch())
+	return c
+}
+
+// Incr records one event for key.
+func (c *WindowCounter[K]) Incr(key K) {
+	epoch := c.epoch()
+	for !c.incr(key, epoch) {
+	}
+	c.sweep(epoch)
+}
+
+// incr counts one event into key's ring, reporting false if the ring was evicted meanwhile.
+func (c *WindowCounter[K]) incr(key K, epoch int64) bool {
+	ring, ok := c.rings.Load(key)
+	if !ok {
+		ring, _ = c.rings.LoadOrStore(key, &windowRing{counts: make([]int, c.buckets), epoch: epoch})
+	}
+	ring.mu.Lock()
+	defer ring.mu.Unlock()
+	if ring.evicted {
+		return false
+	}
+	ring.advance(epoch)
+	ring.counts[ring.head]++
+	return true
+}
+
+// sweep evicts the rings of keys with no events left in the window, if a whole window has
+// passed since the last sweep.
+func (c *WindowCounter[K]) sweep(epoch int64) {
+	last := c.swept.Load()
+	if epoch-last < int64(c.buckets) || !c.swept.CompareAndSwap(last, epoch) {
+		return
+	}
+	c.rings.Range(func(key K, ring *windowRing) bool {
+		ring.mu.Lock()
+		defer ring.mu.Unlock()
+		if epoch-ring.epoch >= int64(c.buckets) {
+			ring.evicted = true
+			c.rings.Delete(key)
+		}
+		return true
+	})
+}
+
+// Count returns the number of events recorded for key within the window.
+func (c *WindowCounter[K]) Count(key K) int {
+	ring, ok := c.rings.Load(key)
+	if !ok {
+		return 0
+	}
+	ring.mu.Lock()
+	defer ring.mu.Unlock()
+	ring.advance(c.epoch())
+	total := 0
+	for _, count := range ring.counts {
+		total += count
+	}
+	return total
+}
+
+// epoch returns the index of the bucket the current time falls in.
+func (c *WindowCounter[K]) epoch() int64 {
+	return now().UnixNano() / int64(c.width)
+}
+
+// advance moves the ring forward to epoch, clearing the buckets that fell out of the window.
+func (r *windowRing) advance(epoch int64) {
+	steps := epoch - r.epoch
+	if steps <= 0 {
+		return
+	}
+	if steps >= int64(len(r.counts)) {
+		clear(r.counts)
+	} else {
+		for ; steps > 0; steps-- {
+			r.head = (r.head + 1) % len(r.counts)
+			r.counts[r.head] = 0
+		}
+	}
+	r.epoch = epoch
+}
@@ -0,0 +1,42 @@
+package asyncmap
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWindowCounterDecay(t *testing.T) {
+	advance := fakeClock(t)
+	c := NewWindowCounter[string](10*time.Second, 10)
+	for i := 0; i < 3; i++ {
+		c.Incr("a")
+	}
+	advance(5 * time.Second)
+	c.Incr("a")
+	if got := c.Count("a"); got != 4 {
+		t.Fatalf("Count after 5s = %d, want 4", got)
+	}
+	advance(6 * time.Second)
+	if got := c.Count("a"); got != 1 {
+		t.Fatalf("Count after 11s = %d, want 1", got)
+	}
+	advance(10 * time.Second)
+	if got := c.Count("a"); got != 0 {
+		t.Fatalf("Count after 21s = %d, want 0", got)
+	}
+}
+
+func TestWindowCounterEvictsIdleKeys(t *testing.T) {
+	advance := fakeClock(t)
+	c := NewWindowCounter[string](10*time.Second, 10)
+	c.Incr("idle")
+	advance(20 * time.Second)
+	c.Incr("busy")
+	if c.rings.Has("idle") {
+		t.Fatal("idle key was not evicted")
+	}
+	c.Incr("idle")
+	if got := c.Count("idle"); got != 1 {
+		t.Fatalf("Count of re-added key = %d, want 1", got)
+	}
+}
@@ -0,0 +1,22 @@
+package asyncmap
+
+import "sync"
+
+// WrapSyncMap returns a SyncMap that shares storage with raw, layering typed access over an
+// existing sync.Map to ease migration from it. Entries already in raw are visible through the
+// returned map and writes through either side are seen by the other. Entries stored in raw
+// with a key that isn't a K or a value that isn't a V are skipped by typed reads and counted
+// by TypeMismatchCount. Len and the other size bookkeeping count raw's entries once, at wrap
+// time; they stay exact only if later writes go through the SyncMap.
+func WrapSyncMap[K comparable, V any](raw *sync.Map) SyncMap[K, V] {
+	m := NewSyncMap[K, V]()
+	m.adoptEntries(raw)
+	return m
+}
+
+// Unwrap returns the sync.Map backing the map, sharing storage as WrapSyncMap does.
+// Writes through it bypass the map's bookkeeping, and it is not meaningful for a forked map.
+func (m *SyncMap[K, V]) Unwrap() *sync.Map {
+	m.lazyInit()
+	return m.rawEntries()
+}
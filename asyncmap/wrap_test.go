@@ -0,0 +1,32 @@
+package asyncmap
+
+import (
+	"maps"
+	"sync"
+	"testing"
+)
+
+func TestWrapSyncMapPrePopulated(t *testing.T) {
+	var raw sync.Map
+	raw.Store("a", 1)
+	raw.Store("b", 2)
+	raw.Store("bad", "not an int")
+	m := WrapSyncMap[string, int](&raw)
+	if want := map[string]int{"a": 1, "b": 2}; !maps.Equal(m.ToMap(), want) {
+		t.Fatalf("wrapped map = %v, want %v", m.ToMap(), want)
+	}
+	if m.TypeMismatchCount() == 0 {
+		t.Fatal("the wrong-typed entry was not counted")
+	}
+	m.Store("c", 3)
+	if value, ok := raw.Load("c"); !ok || value != 3 {
+		t.Fatalf("raw.Load(c) = (%v, %v), want the write made through the wrapper", value, ok)
+	}
+	m.Delete("bad")
+	if m.Len() != 3 {
+		t.Fatalf("Len = %d, want 3", m.Len())
+	}
+	if m.Unwrap() != &raw {
+		t.Fatal("Unwrap did not return the wrapped sync.Map")
+	}
+}
@@ -0,0 +1,26 @@
+package asyncmap
+
+// The YAML hooks below follow the method signatures recognized by gopkg.in/yaml.v2
+// and gopkg.in/yaml.v3, so they work without this package importing a YAML library.
+// Users who don't use YAML don't pull in any extra dependency.
+
+// MarshalYAML returns a snapshot of the map as a plain Go map, so YAML encoders
+// render a SyncMap like any other mapping. Unlike the rest of the API it has a value
+// receiver: YAML encoders only look for Marshaler on the value itself, so a pointer
+// method would be missed on SyncMap fields held by value in a config struct.
+func (m SyncMap[K, V]) MarshalYAML() (any, error) {
+	return m.ToMap(), nil
+}
+
+// UnmarshalYAML decodes a YAML mapping into the map, storing every decoded entry.
+// Existing entries whose keys are not present in the document are left untouched.
+func (m *SyncMap[K, V]) UnmarshalYAML(unmarshal func(any) error) error {
+	decoded := make(map[K]V)
+	if err := unmarshal(&decoded); err != nil {
+		return err
+	}
+	for key, value := range decoded {
+		m.Store(key, value)
+	}
+	return nil
+}
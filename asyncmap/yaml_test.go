@@ -0,0 +1,34 @@
+package asyncmap
+
+import (
+	"encoding/json"
+	"maps"
+	"testing"
+)
+
+// The YAML hooks are exercised without a YAML library: MarshalYAML's result is what an
+// encoder would render, and UnmarshalYAML is handed a decode function as yaml.v3 would.
+
+func TestYAMLRoundTrip(t *testing.T) {
+	in := NewSyncMap(map[string]int{"a": 1, "b": 2})
+	rendered, err := in.MarshalYAML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	plain, ok := rendered.(map[string]int)
+	if !ok || !maps.Equal(plain, in.ToMap()) {
+		t.Fatalf("MarshalYAML = %#v", rendered)
+	}
+	document, err := json.Marshal(plain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := NewSyncMap(map[string]int{"keep": 3})
+	if err := out.UnmarshalYAML(func(v any) error { return json.Unmarshal(document, v) }); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]int{"a": 1, "b": 2, "keep": 3}
+	if !maps.Equal(out.ToMap(), want) {
+		t.Fatalf("UnmarshalYAML left %v, want %v", out.ToMap(), want)
+	}
+}